@@ -2,11 +2,9 @@ package bind
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/http"
 	"regexp"
-	"strconv"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	acquiring "github.com/helios-ag/sberbank-acquiring-go"
@@ -18,11 +16,17 @@ type Client struct {
 	API acquiring.API
 }
 
+// NewClient creates a Client that sends requests through api,
+// usually a client created with acquiring.NewClient.
+func NewClient(api acquiring.API) Client {
+	return Client{API: api}
+}
+
 // Binding is used to make binding related requests
 type Binding struct {
-	BindingID  string
-	NewExpiry  string
-	JSONParams map[string]string
+	BindingID  string            `form:"bindingId"`
+	NewExpiry  string            `form:"newExpiry,omitempty"`
+	JSONParams map[string]string `form:"jsonParams,omitempty"`
 }
 
 func (binding Binding) Validate() error {
@@ -65,10 +69,12 @@ var bind = func(ctx context.Context, client Client, path string, binding Binding
 		return nil, nil, err
 	}
 
-	body := make(map[string]string)
-	body["bindingId"] = binding.BindingID
+	body := Binding{
+		BindingID:  binding.BindingID,
+		JSONParams: binding.JSONParams,
+	}
 
-	return client.bind(ctx, path, body, binding.JSONParams)
+	return client.bind(ctx, path, body)
 }
 
 // ExtendBinding request
@@ -86,27 +92,19 @@ func (c Client) ExtendBinding(ctx context.Context, binding Binding) (*schema.Res
 		return nil, nil, err
 	}
 
-	body := make(map[string]string)
-	body["bindingId"] = binding.BindingID
-	body["newExpiry"] = binding.NewExpiry
-
-	return c.bind(ctx, path, body, binding.JSONParams)
+	return c.bind(ctx, path, binding)
 }
 
-func (c Client) bind(ctx context.Context, path string, body map[string]string, jsonParams map[string]string) (*schema.Response, *http.Response, error) {
-	var response schema.Response
-	req, err := c.API.NewRestRequest(ctx, http.MethodGet, path, body, jsonParams)
+func (c Client) bind(ctx context.Context, path string, body Binding) (*schema.Response, *http.Response, error) {
+	op := acquiring.Operation[Binding, schema.Response]{Method: http.MethodGet, Path: path}
 
-	if err != nil {
-		return nil, nil, err
-	}
-	result, err := c.API.Do(req, &response)
-	if err != nil {
-		return nil, result, err
-	}
-	_ = json.NewDecoder(result.Body).Decode(&response)
+	return acquiring.Call(ctx, c.API, op, body)
+}
 
-	return &response, result, err
+// getBindingsRequest is the body of getBindings.do request
+type getBindingsRequest struct {
+	ClientId   string            `form:"clientId"`
+	JSONParams map[string]string `form:"jsonParams,omitempty"`
 }
 
 // GetBindings request
@@ -124,35 +122,28 @@ func (c Client) GetBindings(ctx context.Context, clientId string, jsonParams map
 		return nil, nil, fmt.Errorf("clientId is too long (max 255)")
 	}
 
-	body := make(map[string]string)
-	body["clientId"] = clientId
-
-	var response schema.BindingsResponse
-	req, err := c.API.NewRestRequest(ctx, http.MethodGet, path, body, jsonParams)
-
-	if err != nil {
-		return nil, nil, err
-	}
-	result, err := c.API.Do(req, &response)
-	if err != nil {
-		return nil, result, err
+	body := getBindingsRequest{
+		ClientId:   clientId,
+		JSONParams: jsonParams,
 	}
-	_ = json.NewDecoder(result.Body).Decode(&response)
 
-	return &response, result, err
+	op := acquiring.Operation[getBindingsRequest, schema.BindingsResponse]{Method: http.MethodGet, Path: path}
+
+	return acquiring.Call(ctx, c.API, op, body)
 }
 
 type GetBindingsRequest struct {
-	PAN         *string
-	UserName    string
-	Password    string
-	BindingID   *string
-	ShowExpired *bool
+	PAN         *string `form:"pan,omitempty"`
+	UserName    string  `form:"userName,omitempty"`
+	Password    string  `form:"password,omitempty"`
+	Token       string  `form:"token,omitempty"`
+	BindingID   *string `form:"bindingId,omitempty"`
+	ShowExpired *bool   `form:"showExpired,omitempty"`
 }
 
 func validateGetBindingRequest(request GetBindingsRequest) error {
-	if request.UserName == "" || request.Password == "" {
-		return fmt.Errorf("userName and Password are required")
+	if err := validateCredentials(request.UserName, request.Password, request.Token); err != nil {
+		return err
 	}
 	if request.PAN == nil && request.BindingID == nil {
 		return fmt.Errorf("either PAN or BindingID must be provided")
@@ -180,50 +171,42 @@ func (c Client) GetBindingsByCardOrId(ctx context.Context, request GetBindingsRe
 		return nil, nil, err
 	}
 
-	body := map[string]string{
-		"userName": request.UserName,
-		"password": request.Password,
-	}
+	op := acquiring.Operation[GetBindingsRequest, schema.BindingsByCardOrIdResponse]{Method: http.MethodPost, Path: path}
 
-	if request.PAN != nil {
-		body["pan"] = *request.PAN
-	}
-	if request.BindingID != nil {
-		body["bindingId"] = *request.BindingID
-	}
-	if request.ShowExpired != nil {
-		body["showExpired"] = strconv.FormatBool(*request.ShowExpired)
-	}
+	return acquiring.Call(ctx, c.API, op, request)
+}
 
-	var response schema.BindingsByCardOrIdResponse
-	req, err := c.API.NewRestRequest(ctx, http.MethodPost, path, body, nil)
+type CreateBindingNoPaymentRequest struct {
+	UserName             string            `form:"userName,omitempty"`
+	Password             string            `form:"password,omitempty"`
+	Token                string            `form:"token,omitempty"`
+	ClientId             string            `form:"clientId"`
+	CardHolderName       string            `form:"cardHolderName"`
+	PAN                  string            `form:"pan"`
+	MerchantLogin        *string           `form:"merchantLogin,omitempty"`
+	ExpiryDate           string            `form:"expiryDate"`
+	AdditionalParameters map[string]string `form:"additionalParameters,omitempty"`
+}
 
-	if err != nil {
-		return nil, nil, err
+func validateCreateBindingNoPaymentRequest(request CreateBindingNoPaymentRequest) error {
+	if err := validateCredentials(request.UserName, request.Password, request.Token); err != nil {
+		return err
 	}
-	result, err := c.API.Do(req, &response)
-	if err != nil {
-		return nil, result, err
+	if request.ClientId == "" || request.CardHolderName == "" || request.PAN == "" || request.ExpiryDate == "" {
+		return fmt.Errorf("ClientId, CardHolderName, PAN, ExpiryDate are required")
 	}
-	_ = json.NewDecoder(result.Body).Decode(&response)
-
-	return &response, result, err
-}
 
-type CreateBindingNoPaymentRequest struct {
-	UserName             string
-	Password             string
-	ClientId             string
-	CardHolderName       string
-	PAN                  string
-	MerchantLogin        *string
-	ExpiryDate           string
-	AdditionalParameters map[string]string
+	return nil
 }
 
-func validateCreateBindingNoPaymentRequest(request CreateBindingNoPaymentRequest) error {
-	if request.UserName == "" || request.Password == "" || request.ClientId == "" || request.CardHolderName == "" || request.PAN == "" || request.ExpiryDate == "" {
-		return fmt.Errorf("userName, Password, ClientId, CardHolderName, PAN, ExpiryDate are required")
+// validateCredentials checks credentials passed with a request,
+// credentials of the client are used when all of them are empty
+func validateCredentials(userName, password, token string) error {
+	if token != "" && (userName != "" || password != "") {
+		return acquiring.ErrMixedCredentials
+	}
+	if (userName == "") != (password == "") {
+		return fmt.Errorf("userName and Password are required together")
 	}
 
 	return nil
@@ -244,37 +227,9 @@ func (c Client) CreateBindingNoPayment(ctx context.Context, request CreateBindin
 		return nil, nil, err
 	}
 
-	body := map[string]string{
-		"userName":       request.UserName,
-		"password":       request.Password,
-		"clientId":       request.ClientId,
-		"cardHolderName": request.CardHolderName,
-		"pan":            request.PAN,
-		"expiryDate":     request.ExpiryDate,
-	}
-
-	if request.AdditionalParameters != nil {
-		var params, _ = json.Marshal(request.AdditionalParameters)
-
-		body["additionalParameters"] = string(params)
-	}
-	if request.MerchantLogin != nil {
-		body["merchantLogin"] = *request.MerchantLogin
-	}
-
-	var response schema.BindingsNoPaymentResponse
-	req, err := c.API.NewRestRequest(ctx, http.MethodPost, path, body, nil)
-
-	if err != nil {
-		return nil, nil, err
-	}
-	result, err := c.API.Do(req, &response)
-	if err != nil {
-		return nil, result, err
-	}
-	_ = json.NewDecoder(result.Body).Decode(&response)
+	op := acquiring.Operation[CreateBindingNoPaymentRequest, schema.BindingsNoPaymentResponse]{Method: http.MethodPost, Path: path}
 
-	return &response, result, err
+	return acquiring.Call(ctx, c.API, op, request)
 }
 
 func getClient() Client {
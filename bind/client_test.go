@@ -3,6 +3,7 @@ package bind
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"testing"
@@ -35,8 +36,7 @@ func prepareClient(URL string) {
 		SessionTimeoutSecs: 1200,
 		SandboxMode:        true,
 	}
-	acquiring.SetConfig(cfg)
-	acquiring.WithEndpoint(URL)
+	acquiring.SetConfig(cfg, acquiring.WithEndpoint(URL))
 }
 
 func TestClient_BindCard(t *testing.T) {
@@ -68,9 +68,11 @@ func TestClient_BindCard(t *testing.T) {
 			BindingID: "fd3afc57-c6d0-4e08-aaef-1b7cfeb093dc",
 		}
 
-		response, _, err := BindCard(context.Background(), binding)
-		Expect(err).ToNot(HaveOccurred())
-		Expect(response).To(PointTo(MatchFields(IgnoreExtras, Fields{
+		_, _, err := BindCard(context.Background(), binding)
+		var apiErr *acquiring.APIError
+		Expect(errors.As(err, &apiErr)).To(BeTrue())
+		Expect(apiErr).To(PointTo(MatchFields(IgnoreExtras, Fields{
+			"Endpoint":     Equal(endpoints.BindCard),
 			"ErrorCode":    Equal(2),
 			"ErrorMessage": Equal("Binding is active"),
 		})))
@@ -114,8 +116,8 @@ func TestClient_BindCard(t *testing.T) {
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusOK)
 			json.NewEncoder(w).Encode(schema.BindingsResponse{
-				ErrorCode:    2,
-				ErrorMessage: "Binding is active",
+				ErrorCode:    0,
+				ErrorMessage: "Success",
 			})
 		})
 
@@ -154,9 +156,11 @@ func TestClient_BindCard(t *testing.T) {
 			BindingID: "fd3afc57-c6d0-4e08-aaef-1b7cfeb093dc",
 		}
 
-		response, _, err := BindCard(context.Background(), binding)
-		Expect(err).ToNot(HaveOccurred())
-		Expect(response).To(PointTo(MatchFields(IgnoreExtras, Fields{
+		_, _, err := BindCard(context.Background(), binding)
+		var apiErr *acquiring.APIError
+		Expect(errors.As(err, &apiErr)).To(BeTrue())
+		Expect(apiErr).To(PointTo(MatchFields(IgnoreExtras, Fields{
+			"Endpoint":     Equal(endpoints.BindCard),
 			"ErrorCode":    Equal(2),
 			"ErrorMessage": Equal("Binding is active"),
 		})))
@@ -0,0 +1,244 @@
+package sberbank_acquiring_go
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without sending the request while the circuit breaker is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open, request is not sent")
+
+// CircuitState is the state of a CircuitBreaker.
+type CircuitState int
+
+const (
+	// CircuitClosed lets all requests through
+	CircuitClosed CircuitState = iota
+	// CircuitOpen rejects all requests with ErrCircuitOpen
+	CircuitOpen
+	// CircuitHalfOpen lets probe requests through to check whether the gateway has recovered
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+
+	return "unknown"
+}
+
+// CircuitBreakerSettings configures a CircuitBreaker. Zero values are replaced with defaults.
+type CircuitBreakerSettings struct {
+	// FailureThreshold is the number of consecutive failures that opens the circuit, 5 by default
+	FailureThreshold int
+	// OpenTimeout is the time the circuit stays open before probe requests are sent, 30 seconds by default
+	OpenTimeout time.Duration
+	// HalfOpenProbes is the number of successful probe requests that closes the circuit, 1 by default.
+	// It is also the number of probes sent concurrently.
+	HalfOpenProbes int
+	// OnStateChange, if set, is called on every state change
+	OnStateChange func(from, to CircuitState)
+}
+
+// CircuitBreaker stops sending requests to a degraded gateway.
+// Transport errors, HTTP 5xx responses and system errors of the gateway count as failures,
+// other responses, including gateway errors like "order not found", count as successes.
+type CircuitBreaker struct {
+	settings CircuitBreakerSettings
+	now      func() time.Time
+
+	mu         sync.Mutex
+	state      CircuitState
+	generation uint64
+	failures   int
+	probes     int
+	successes  int
+	openedAt   time.Time
+}
+
+// NewCircuitBreaker creates a CircuitBreaker, which can be shared by several clients with WithCircuitBreaker.
+func NewCircuitBreaker(settings CircuitBreakerSettings) *CircuitBreaker {
+	if settings.FailureThreshold <= 0 {
+		settings.FailureThreshold = 5
+	}
+	if settings.OpenTimeout <= 0 {
+		settings.OpenTimeout = 30 * time.Second
+	}
+	if settings.HalfOpenProbes <= 0 {
+		settings.HalfOpenProbes = 1
+	}
+
+	return &CircuitBreaker{settings: settings, now: time.Now}
+}
+
+// WithCircuitBreaker configures a Client to send requests through the circuit breaker.
+func WithCircuitBreaker(breaker *CircuitBreaker) ClientOption {
+	return func(c *Client) {
+		c.breaker = breaker
+	}
+}
+
+// State returns the current state of the circuit breaker.
+func (b *CircuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.state
+}
+
+// allow reports whether the request can be sent and returns the generation to record its outcome with.
+func (b *CircuitBreaker) allow() (uint64, error) {
+	b.mu.Lock()
+	var changed func()
+	defer func() {
+		b.mu.Unlock()
+		if changed != nil {
+			changed()
+		}
+	}()
+
+	if b.state == CircuitOpen && b.now().Sub(b.openedAt) >= b.settings.OpenTimeout {
+		changed = b.setState(CircuitHalfOpen)
+	}
+
+	switch b.state {
+	case CircuitOpen:
+		return 0, ErrCircuitOpen
+	case CircuitHalfOpen:
+		if b.probes >= b.settings.HalfOpenProbes {
+			return 0, ErrCircuitOpen
+		}
+		b.probes++
+	}
+
+	return b.generation, nil
+}
+
+// record counts the outcome of a request allowed in the generation.
+// Outcomes of requests allowed before the last state change are ignored.
+func (b *CircuitBreaker) record(generation uint64, outcome breakerOutcome) {
+	b.mu.Lock()
+	var changed func()
+	defer func() {
+		b.mu.Unlock()
+		if changed != nil {
+			changed()
+		}
+	}()
+
+	if generation != b.generation {
+		return
+	}
+
+	switch b.state {
+	case CircuitClosed:
+		switch outcome {
+		case breakerSuccess:
+			b.failures = 0
+		case breakerFailure:
+			b.failures++
+			if b.failures >= b.settings.FailureThreshold {
+				changed = b.setState(CircuitOpen)
+			}
+		}
+	case CircuitHalfOpen:
+		b.probes--
+		switch outcome {
+		case breakerSuccess:
+			b.successes++
+			if b.successes >= b.settings.HalfOpenProbes {
+				changed = b.setState(CircuitClosed)
+			}
+		case breakerFailure:
+			changed = b.setState(CircuitOpen)
+		}
+	}
+}
+
+// setState changes the state and returns the callback to be called after unlock.
+func (b *CircuitBreaker) setState(state CircuitState) func() {
+	from := b.state
+	b.state = state
+	b.generation++
+	b.failures = 0
+	b.probes = 0
+	b.successes = 0
+	if state == CircuitOpen {
+		b.openedAt = b.now()
+	}
+
+	if b.settings.OnStateChange == nil {
+		return nil
+	}
+
+	return func() {
+		b.settings.OnStateChange(from, state)
+	}
+}
+
+// enter checks the circuit breaker of the client and returns a function recording the outcome of the request.
+func (c *Client) enter() (func(ctx context.Context, err error), error) {
+	if c.breaker == nil {
+		return func(context.Context, error) {}, nil
+	}
+
+	generation, err := c.breaker.allow()
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, err error) {
+		c.breaker.record(generation, breakerOutcomeOf(ctx, err))
+	}, nil
+}
+
+type breakerOutcome int
+
+const (
+	breakerSuccess breakerOutcome = iota
+	breakerFailure
+	// breakerIgnored is the outcome of requests cancelled by the caller
+	breakerIgnored
+)
+
+// breakerOutcomeOf classifies the result of a request.
+func breakerOutcomeOf(ctx context.Context, err error) breakerOutcome {
+	if err == nil {
+		return breakerSuccess
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		if ctx.Err() != nil {
+			return breakerIgnored
+		}
+		return breakerFailure
+	}
+
+	if apiErr.StatusCode >= http.StatusInternalServerError {
+		return breakerFailure
+	}
+	// code 7 is a system error of the gateway, even where it is documented together with a wrong order state
+	if apiErr.ErrorCode == 7 {
+		return breakerFailure
+	}
+	info, ok := apiErr.Info()
+	if ok && info.Meaning == MeaningSystemError {
+		return breakerFailure
+	}
+	if !ok && apiErr.ErrorCode != 0 && apiErr.Class() == ErrorClassNeedsReconciliation {
+		// unknown codes of methods that change orders and bindings
+		return breakerFailure
+	}
+
+	return breakerSuccess
+}
@@ -0,0 +1,203 @@
+package sberbank_acquiring_go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/helios-ag/sberbank-acquiring-go/endpoints"
+	server "github.com/helios-ag/sberbank-acquiring-go/testing"
+	. "github.com/onsi/gomega"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	RegisterTestingT(t)
+
+	testServer := server.NewServer()
+	defer testServer.Teardown()
+
+	var response atomic.Value
+	var requests atomic.Int32
+	testServer.Mux.HandleFunc(endpoints.GetOrderStatusExtended, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		http.Error(w, "Bad Gateway", http.StatusBadGateway)
+	})
+	testServer.Mux.HandleFunc(endpoints.Deposit, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch response.Load().(string) {
+		case "system error":
+			fmt.Fprint(w, `{"errorCode":"7","errorMessage":"Системная ошибка"}`)
+		case "unknown code":
+			fmt.Fprint(w, `{"errorCode":"99","errorMessage":"Неизвестная ошибка"}`)
+		case "not found":
+			fmt.Fprint(w, `{"errorCode":"6","errorMessage":"Заказ не найден"}`)
+		default:
+			fmt.Fprint(w, `{"errorCode":"0","errorMessage":"Успешно"}`)
+		}
+	})
+	testServer.Mux.HandleFunc(endpoints.Register, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch response.Load().(string) {
+		case "5xx":
+			http.Error(w, "Bad Gateway", http.StatusBadGateway)
+		case "system error":
+			fmt.Fprint(w, `{"errorCode":"7","errorMessage":"Системная ошибка"}`)
+		case "already processed":
+			fmt.Fprint(w, `{"errorCode":"1","errorMessage":"Заказ с таким номером уже обработан"}`)
+		default:
+			fmt.Fprint(w, `{"orderId":"70906e55","formUrl":"https://form"}`)
+		}
+	})
+
+	type change struct{ from, to CircuitState }
+	newBreaker := func(settings CircuitBreakerSettings) (*CircuitBreaker, *[]change, *time.Time) {
+		var changes []change
+		now := time.Now()
+		settings.OnStateChange = func(from, to CircuitState) {
+			changes = append(changes, change{from, to})
+		}
+		breaker := NewCircuitBreaker(settings)
+		breaker.now = func() time.Time { return now }
+
+		return breaker, &changes, &now
+	}
+
+	t.Run("Trips on consecutive failures and recovers with probes", func(t *testing.T) {
+		breaker, changes, now := newBreaker(CircuitBreakerSettings{FailureThreshold: 3, OpenTimeout: time.Minute, HalfOpenProbes: 2})
+		client, err := NewClient(testConfig, WithEndpoint(testServer.URL), WithCircuitBreaker(breaker))
+		Expect(err).ToNot(HaveOccurred())
+
+		response.Store("5xx")
+		Expect(sendRequest(context.Background(), client, endpoints.Register)).ToNot(Succeed())
+		response.Store("system error")
+		Expect(sendRequest(context.Background(), client, endpoints.Register)).ToNot(Succeed())
+		Expect(breaker.State()).To(Equal(CircuitClosed))
+		response.Store("5xx")
+		Expect(sendRequest(context.Background(), client, endpoints.Register)).ToNot(Succeed())
+		Expect(breaker.State()).To(Equal(CircuitOpen))
+
+		requests.Store(0)
+		Expect(sendRequest(context.Background(), client, endpoints.Register)).To(MatchError(ErrCircuitOpen))
+		Expect(requests.Load()).To(BeZero())
+
+		*now = now.Add(time.Minute)
+		response.Store("ok")
+		Expect(sendRequest(context.Background(), client, endpoints.Register)).To(Succeed())
+		Expect(breaker.State()).To(Equal(CircuitHalfOpen))
+		Expect(sendRequest(context.Background(), client, endpoints.Register)).To(Succeed())
+		Expect(breaker.State()).To(Equal(CircuitClosed))
+
+		Expect(*changes).To(Equal([]change{
+			{CircuitClosed, CircuitOpen},
+			{CircuitOpen, CircuitHalfOpen},
+			{CircuitHalfOpen, CircuitClosed},
+		}))
+	})
+
+	t.Run("Failed probe opens the circuit again", func(t *testing.T) {
+		breaker, changes, now := newBreaker(CircuitBreakerSettings{FailureThreshold: 1, OpenTimeout: time.Second})
+		client, err := NewClient(testConfig, WithEndpoint(testServer.URL), WithCircuitBreaker(breaker))
+		Expect(err).ToNot(HaveOccurred())
+
+		response.Store("5xx")
+		Expect(sendRequest(context.Background(), client, endpoints.Register)).ToNot(Succeed())
+		*now = now.Add(time.Second)
+		Expect(sendRequest(context.Background(), client, endpoints.Register)).ToNot(Succeed())
+		Expect(breaker.State()).To(Equal(CircuitOpen))
+		Expect(sendRequest(context.Background(), client, endpoints.Register)).To(MatchError(ErrCircuitOpen))
+
+		Expect(*changes).To(Equal([]change{
+			{CircuitClosed, CircuitOpen},
+			{CircuitOpen, CircuitHalfOpen},
+			{CircuitHalfOpen, CircuitOpen},
+		}))
+	})
+
+	t.Run("Only allowed number of probes is sent", func(t *testing.T) {
+		breaker, _, now := newBreaker(CircuitBreakerSettings{FailureThreshold: 1, OpenTimeout: time.Second})
+		generation, err := breaker.allow()
+		Expect(err).ToNot(HaveOccurred())
+		breaker.record(generation, breakerFailure)
+
+		*now = now.Add(time.Second)
+		probe, err := breaker.allow()
+		Expect(err).ToNot(HaveOccurred())
+		_, err = breaker.allow()
+		Expect(err).To(MatchError(ErrCircuitOpen))
+
+		breaker.record(probe, breakerIgnored)
+		_, err = breaker.allow()
+		Expect(err).ToNot(HaveOccurred(), "cancelled probe frees its slot")
+	})
+
+	t.Run("Gateway errors and successes reset failures", func(t *testing.T) {
+		breaker, _, _ := newBreaker(CircuitBreakerSettings{FailureThreshold: 2})
+		client, err := NewClient(testConfig, WithEndpoint(testServer.URL), WithCircuitBreaker(breaker))
+		Expect(err).ToNot(HaveOccurred())
+
+		for _, outcome := range []string{"5xx", "already processed", "5xx", "ok", "5xx"} {
+			response.Store(outcome)
+			_ = sendRequest(context.Background(), client, endpoints.Register)
+		}
+		Expect(breaker.State()).To(Equal(CircuitClosed))
+	})
+
+	t.Run("System errors of deposit trip the circuit", func(t *testing.T) {
+		breaker, _, _ := newBreaker(CircuitBreakerSettings{FailureThreshold: 2})
+		client, err := NewClient(testConfig, WithEndpoint(testServer.URL), WithCircuitBreaker(breaker))
+		Expect(err).ToNot(HaveOccurred())
+
+		response.Store("system error")
+		Expect(sendRequest(context.Background(), client, endpoints.Deposit)).To(MatchError(ErrWrongOrderState))
+		Expect(breaker.State()).To(Equal(CircuitClosed))
+		Expect(sendRequest(context.Background(), client, endpoints.Deposit)).ToNot(Succeed())
+		Expect(breaker.State()).To(Equal(CircuitOpen))
+	})
+
+	t.Run("Unknown codes of deposit trip the circuit", func(t *testing.T) {
+		breaker, _, _ := newBreaker(CircuitBreakerSettings{FailureThreshold: 2})
+		client, err := NewClient(testConfig, WithEndpoint(testServer.URL), WithCircuitBreaker(breaker))
+		Expect(err).ToNot(HaveOccurred())
+
+		response.Store("unknown code")
+		Expect(sendRequest(context.Background(), client, endpoints.Deposit)).ToNot(Succeed())
+		Expect(sendRequest(context.Background(), client, endpoints.Deposit)).ToNot(Succeed())
+		Expect(breaker.State()).To(Equal(CircuitOpen))
+
+		breaker, _, _ = newBreaker(CircuitBreakerSettings{FailureThreshold: 2})
+		client, err = NewClient(testConfig, WithEndpoint(testServer.URL), WithCircuitBreaker(breaker))
+		Expect(err).ToNot(HaveOccurred())
+
+		response.Store("not found")
+		Expect(sendRequest(context.Background(), client, endpoints.Deposit)).ToNot(Succeed())
+		Expect(sendRequest(context.Background(), client, endpoints.Deposit)).ToNot(Succeed())
+		Expect(breaker.State()).To(Equal(CircuitClosed), "business errors don't trip the circuit")
+	})
+
+	t.Run("Requests cancelled by caller are ignored", func(t *testing.T) {
+		breaker, _, _ := newBreaker(CircuitBreakerSettings{FailureThreshold: 1})
+		client, err := NewClient(testConfig, WithEndpoint(testServer.URL), WithCircuitBreaker(breaker))
+		Expect(err).ToNot(HaveOccurred())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		Expect(sendRequest(ctx, client, endpoints.Register)).To(MatchError(context.Canceled))
+		Expect(breaker.State()).To(Equal(CircuitClosed))
+	})
+
+	t.Run("Open circuit is not retried", func(t *testing.T) {
+		breaker, _, _ := newBreaker(CircuitBreakerSettings{FailureThreshold: 1})
+		client, err := NewClient(testConfig, WithEndpoint(testServer.URL), WithCircuitBreaker(breaker),
+			WithRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}))
+		Expect(err).ToNot(HaveOccurred())
+
+		requests.Store(0)
+		err = sendRequest(context.Background(), client, endpoints.GetOrderStatusExtended)
+		Expect(errors.Is(err, ErrCircuitOpen)).To(BeTrue(), "unexpected error %v", err)
+		Expect(requests.Load()).To(BeEquivalentTo(1))
+	})
+}
@@ -0,0 +1,54 @@
+package sberbank_acquiring_go
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/helios-ag/sberbank-acquiring-go/form"
+)
+
+// Form is the body of a REST request: form values and jsonParams.
+type Form struct {
+	Params     map[string]string
+	JSONParams map[string]string
+}
+
+// Operation describes a gateway method: HTTP method, API path, request and response types.
+//
+//	var deposit = acquiring.Operation[acquiring.Form, schema.OrderResponse]{
+//		Method: http.MethodPost,
+//		Path:   endpoints.Deposit,
+//	}
+type Operation[Req, Resp any] struct {
+	Method string
+	Path   string
+}
+
+// Call sends req to the gateway and decodes the response into Resp.
+// Form requests and structs with form tags (see package form) are sent with API.NewRestRequest,
+// other requests are encoded to JSON with API.NewRequest. The response body is decoded once, by API.Do.
+func Call[Req, Resp any](ctx context.Context, api API, op Operation[Req, Resp], req Req) (*Resp, *http.Response, error) {
+	var httpReq *http.Request
+	var err error
+	if f, ok := any(req).(Form); ok {
+		httpReq, err = api.NewRestRequest(ctx, op.Method, op.Path, f.Params, f.JSONParams)
+	} else if form.HasTags(req) {
+		var params map[string]string
+		if params, err = form.Encode(req); err == nil {
+			httpReq, err = api.NewRestRequest(ctx, op.Method, op.Path, params, nil)
+		}
+	} else {
+		httpReq, err = api.NewRequest(ctx, op.Method, op.Path, req)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var resp Resp
+	result, err := api.Do(httpReq, &resp)
+	if err != nil {
+		return nil, result, err
+	}
+
+	return &resp, result, nil
+}
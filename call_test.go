@@ -0,0 +1,192 @@
+package sberbank_acquiring_go
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/helios-ag/sberbank-acquiring-go/endpoints"
+	server "github.com/helios-ag/sberbank-acquiring-go/testing"
+	. "github.com/onsi/gomega"
+)
+
+type orderResponse struct {
+	OrderId      string `json:"orderId"`
+	FormUrl      string `json:"formUrl"`
+	ErrorCode    int    `json:"errorCode,string,omitempty"`
+	ErrorMessage string `json:"errorMessage,omitempty"`
+}
+
+type countingReader struct {
+	io.Reader
+	reads *int
+}
+
+func (r countingReader) Read(p []byte) (int, error) {
+	*r.reads++
+	return r.Reader.Read(p)
+}
+
+func TestCall(t *testing.T) {
+	RegisterTestingT(t)
+
+	testServer := server.NewServer()
+	defer testServer.Teardown()
+
+	var contentType string
+	testServer.Mux.HandleFunc(endpoints.Register, func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		fmt.Fprint(w, `{"orderId":"70906e55","formUrl":"https://form"}`)
+	})
+	testServer.Mux.HandleFunc(endpoints.ApplePay, func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		fmt.Fprintf(w, `{"success":true,"data":{"orderId":"%s"}}`, body["orderNumber"])
+	})
+	testServer.Mux.HandleFunc(endpoints.Deposit, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"errorCode":"6","errorMessage":"Заказ не найден"}`)
+	})
+
+	client, err := NewClient(ClientConfig{UserName: "test-api", Password: "test"}, WithEndpoint(testServer.URL))
+	Expect(err).ToNot(HaveOccurred())
+
+	t.Run("Form request", func(t *testing.T) {
+		op := Operation[Form, orderResponse]{Method: http.MethodPost, Path: endpoints.Register}
+		resp, result, err := Call(context.Background(), client, op, Form{Params: map[string]string{"orderNumber": "42"}})
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.StatusCode).To(Equal(http.StatusOK))
+		Expect(contentType).To(Equal("application/x-www-form-urlencoded"))
+		Expect(resp.OrderId).To(Equal("70906e55"))
+		Expect(resp.FormUrl).To(Equal("https://form"))
+	})
+
+	t.Run("JSON request", func(t *testing.T) {
+		type paymentResponse struct {
+			Success bool `json:"success"`
+			Data    struct {
+				OrderId string `json:"orderId"`
+			} `json:"data"`
+		}
+		op := Operation[map[string]string, paymentResponse]{Method: http.MethodPost, Path: endpoints.ApplePay}
+		resp, _, err := Call(context.Background(), client, op, map[string]string{"orderNumber": "42"})
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(contentType).To(Equal("application/json"))
+		Expect(resp.Success).To(BeTrue())
+		Expect(resp.Data.OrderId).To(Equal("42"))
+	})
+
+	t.Run("Gateway error", func(t *testing.T) {
+		op := Operation[Form, orderResponse]{Method: http.MethodPost, Path: endpoints.Deposit}
+		resp, result, err := Call(context.Background(), client, op, Form{})
+
+		Expect(err).To(MatchError(ErrOrderNotFound))
+		Expect(resp).To(BeNil())
+		Expect(result.StatusCode).To(Equal(http.StatusOK))
+	})
+
+	t.Run("Request error", func(t *testing.T) {
+		op := Operation[Form, orderResponse]{Method: http.MethodPost, Path: endpoints.Register}
+		api := &failingAPI{API: client, err: errors.New("error happened")}
+		resp, result, err := Call(context.Background(), api, op, Form{})
+
+		Expect(err).To(MatchError("error happened"))
+		Expect(resp).To(BeNil())
+		Expect(result).To(BeNil())
+	})
+
+	t.Run("Response body is read once", func(t *testing.T) {
+		reads := 0
+		reader = func(r io.Reader) ([]byte, error) {
+			return io.ReadAll(countingReader{Reader: r, reads: &reads})
+		}
+		defer func() {
+			reader = func(r io.Reader) ([]byte, error) {
+				return io.ReadAll(r)
+			}
+		}()
+
+		op := Operation[Form, orderResponse]{Method: http.MethodPost, Path: endpoints.Register}
+		_, result, err := Call(context.Background(), client, op, Form{})
+		Expect(err).ToNot(HaveOccurred())
+
+		readsAfterCall := reads
+		Expect(readsAfterCall).To(BeNumerically(">", 0))
+
+		body, _ := io.ReadAll(result.Body)
+		Expect(string(body)).To(ContainSubstring("70906e55"))
+		Expect(reads).To(Equal(readsAfterCall))
+	})
+}
+
+type failingAPI struct {
+	API
+	err error
+}
+
+func (f *failingAPI) NewRestRequest(ctx context.Context, method, urlPath string, data map[string]string, jsonParams map[string]string) (*http.Request, error) {
+	return nil, f.err
+}
+
+// staticTransport responds to every request with the same body.
+type staticTransport string
+
+func (s staticTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(string(s))),
+		Request:    r,
+	}, nil
+}
+
+func benchmarkClient(b *testing.B) *Client {
+	body := `{"orderId":"70906e55-7114-41d6-8332-4609dc6590f4","formUrl":"https://3dsec.sberbank.ru/payment/merchants/test/payment_ru.html?mdOrder=70906e55-7114-41d6-8332-4609dc6590f4"}`
+	client, err := NewClient(ClientConfig{UserName: "test-api", Password: "test"}, WithHTTPClient(&http.Client{Transport: staticTransport(body)}))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	return client
+}
+
+// BenchmarkCall compares Call with the former pattern of the sub-packages,
+// which decoded the response body a second time after Do.
+func BenchmarkCall(b *testing.B) {
+	params := map[string]string{"orderNumber": "42", "amount": "100", "returnUrl": "https://shop/success"}
+
+	b.Run("Call", func(b *testing.B) {
+		client := benchmarkClient(b)
+		op := Operation[Form, orderResponse]{Method: http.MethodPost, Path: endpoints.Register}
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, _, err := Call(context.Background(), client, op, Form{Params: params}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("Do and decode again", func(b *testing.B) {
+		client := benchmarkClient(b)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			req, err := client.NewRestRequest(context.Background(), http.MethodPost, endpoints.Register, params, nil)
+			if err != nil {
+				b.Fatal(err)
+			}
+			var response orderResponse
+			result, err := client.Do(req, &response)
+			if err != nil {
+				b.Fatal(err)
+			}
+			_ = json.NewDecoder(result.Body).Decode(&response)
+		}
+	})
+}
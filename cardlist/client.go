@@ -2,7 +2,6 @@ package cardlist
 
 import (
 	"context"
-	"encoding/json"
 	"net/http"
 
 	acquiring "github.com/helios-ag/sberbank-acquiring-go"
@@ -14,6 +13,12 @@ type Client struct {
 	API acquiring.API
 }
 
+// NewClient creates a Client that sends requests through api,
+// usually a client created with acquiring.NewClient.
+func NewClient(api acquiring.API) Client {
+	return Client{API: api}
+}
+
 // UpdateSSLCardList request
 // see https://securepayments.sberbank.ru/wiki/doku.php/integration:api:rest:requests:updateSSLCardList
 func UpdateSSLCardList(ctx context.Context, mdorder string, jsonParams map[string]string) (*schema.Response, *http.Response, error) {
@@ -24,22 +29,20 @@ func UpdateSSLCardList(ctx context.Context, mdorder string, jsonParams map[strin
 // see https://securepayments.sberbank.ru/wiki/doku.php/integration:api:rest:requests:updateSSLCardList
 func (c Client) UpdateSSLCardList(ctx context.Context, mdorder string, jsonParams map[string]string) (*schema.Response, *http.Response, error) {
 	path := endpoints.UpdateSSLCardList
-	body := make(map[string]string)
-	body["mdorder"] = mdorder
+	body := updateSSLCardListRequest{
+		MdOrder:    mdorder,
+		JSONParams: jsonParams,
+	}
 
-	var response schema.Response
-	req, err := c.API.NewRestRequest(ctx, http.MethodGet, path, body, jsonParams)
+	op := acquiring.Operation[updateSSLCardListRequest, schema.Response]{Method: http.MethodGet, Path: path}
 
-	if err != nil {
-		return nil, nil, err
-	}
-	result, err := c.API.Do(req, &response)
-	if err != nil {
-		return nil, result, err
-	}
-	_ = json.NewDecoder(result.Body).Decode(&response)
+	return acquiring.Call(ctx, c.API, op, body)
+}
 
-	return &response, result, err
+// updateSSLCardListRequest is the body of updateSSLCardList.do request
+type updateSSLCardListRequest struct {
+	MdOrder    string            `form:"mdorder"`
+	JSONParams map[string]string `form:"jsonParams,omitempty"`
 }
 
 func getClient() Client {
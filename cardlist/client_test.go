@@ -33,8 +33,7 @@ func prepareClient(URL string) {
 		SessionTimeoutSecs: 1200,
 		SandboxMode:        true,
 	}
-	acquiring.SetConfig(cfg)
-	acquiring.WithEndpoint(URL)
+	acquiring.SetConfig(cfg, acquiring.WithEndpoint(URL))
 }
 
 func TestClient_UpdateSSLCardList(t *testing.T) {
@@ -12,11 +12,12 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
-	"github.com/helios-ag/sberbank-acquiring-go/schema"
+	"github.com/helios-ag/sberbank-acquiring-go/endpoints"
 )
 
-// URLS for API endpoints
+// URLS for API endpoints of Sberbank, see Gateway for other acquirers
 const (
 	APIURI        string = "https://securepayments.sberbank.ru"
 	APISandboxURI string = "https://3dsec.sberbank.ru"
@@ -30,6 +31,9 @@ type APIs struct {
 
 var apis APIs
 
+// ErrMixedCredentials is returned when a request would carry both token and userName/password.
+var ErrMixedCredentials = errors.New("token and Login/Password can't be used together")
+
 // ClientConfig is used to set client configuration
 type ClientConfig struct {
 	UserName           string
@@ -52,6 +56,16 @@ type API interface {
 type Client struct {
 	Config     *ClientConfig
 	httpClient *http.Client
+	timeout    time.Duration
+	userAgent  string
+	retry      RetryPolicy
+	middleware []Middleware
+	limiters   []*limiter
+	breaker    *CircuitBreaker
+	gateway    *Gateway
+	dryRun     bool
+	transport  transportOptions
+	err        error
 }
 
 // Body struct
@@ -69,15 +83,59 @@ type Body struct {
 type ClientOption func(*Client)
 
 // WithToken configures a Client to use the specified token for authentication.
-func WithToken(token string) {
-	cfg.token = token
-	cfg.Password = ""
-	cfg.UserName = ""
+func WithToken(token string) ClientOption {
+	return func(c *Client) {
+		c.Config.token = token
+		c.Config.Password = ""
+		c.Config.UserName = ""
+	}
 }
 
 // WithEndpoint configures a Client to use the specified API endpoint.
-func WithEndpoint(endpoint string) {
-	cfg.endpoint = strings.TrimRight(endpoint, "/")
+func WithEndpoint(endpoint string) ClientOption {
+	return func(c *Client) {
+		c.Config.endpoint = strings.TrimRight(endpoint, "/")
+	}
+}
+
+// WithHTTPClient configures a Client to send requests with the specified http.Client.
+// Its timeout is used instead of DefaultTimeout.
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *Client) {
+		if httpClient != nil {
+			c.httpClient = httpClient
+			c.transport.prepared = false
+		}
+	}
+}
+
+// WithTimeout configures a Client to limit the time of a single request.
+// The http.Client passed with WithHTTPClient is not modified, a copy is used instead.
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithUserAgent configures a Client to send the specified User-Agent header.
+func WithUserAgent(userAgent string) ClientOption {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithLanguage configures a Client to send the specified language with REST requests.
+func WithLanguage(language string) ClientOption {
+	return func(c *Client) {
+		c.Config.Language = language
+	}
+}
+
+// WithSandbox configures a Client to use sandbox or production API.
+func WithSandbox(sandbox bool) ClientOption {
+	return func(c *Client) {
+		c.Config.SandboxMode = sandbox
+	}
 }
 
 // NewRestRequest creates an HTTP request against the API with 'rest' in path. The returned request
@@ -87,27 +145,39 @@ func (c *Client) NewRestRequest(ctx context.Context, method, urlPath string, dat
 }
 
 var NewRestRequest = func(c *Client, ctx context.Context, method, urlPath string, data map[string]string, jsonParams map[string]string) (*http.Request, error) {
-	uri := APIURI + urlPath
-
-	if c.Config.SandboxMode {
-		uri = APISandboxURI + urlPath
-	}
-
-	if c.Config.endpoint != "" {
-		uri = c.Config.endpoint + urlPath
+	uri, err := c.url(urlPath)
+	if err != nil {
+		return nil, err
 	}
 
-	jsonParamsEncoded, _ := json.Marshal(jsonParams)
-
 	body := url.Values{}
-	body.Add("userName", c.Config.UserName)
-	body.Add("password", c.Config.Password)
-	body.Add("currency", strconv.Itoa(c.Config.Currency))
-	body.Add("jsonParams", string(jsonParamsEncoded))
-	body.Add("sessionTimeoutSecs", strconv.Itoa(c.Config.SessionTimeoutSecs))
+	if !hasCredentials(data) {
+		if c.Config.token != "" {
+			body.Add("token", c.Config.token)
+		} else {
+			body.Add("userName", c.Config.UserName)
+			body.Add("password", c.Config.Password)
+		}
+	}
+	if c.Config.Currency != 0 {
+		body.Add("currency", strconv.Itoa(c.Config.Currency))
+	}
+	if len(jsonParams) > 0 {
+		jsonParamsEncoded, _ := json.Marshal(jsonParams)
+		body.Add("jsonParams", string(jsonParamsEncoded))
+	}
+	if c.Config.SessionTimeoutSecs != 0 {
+		body.Add("sessionTimeoutSecs", strconv.Itoa(c.Config.SessionTimeoutSecs))
+	}
+	if c.Config.Language != "" {
+		body.Add("language", c.Config.Language)
+	}
 
 	for key, value := range data {
-		body.Add(key, value)
+		body.Set(key, value)
+	}
+	if body.Get("token") != "" && (body.Get("userName") != "" || body.Get("password") != "") {
+		return nil, ErrMixedCredentials
 	}
 	reqData := body.Encode()
 	req, err := http.NewRequest(method, uri, strings.NewReader(reqData))
@@ -118,15 +188,18 @@ var NewRestRequest = func(c *Client, ctx context.Context, method, urlPath string
 
 	req.Header.Set("Cache-Control", "no-cache")
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
 
-	req = req.WithContext(ctx)
+	req = req.WithContext(withEndpoint(ctx, urlPath))
 	return req, nil
 }
 
 // NewRequest creates an HTTP request against the API (mobile payments). The returned request
 // is assigned with ctx and has all necessary headers set (auth, user agent, etc.).
-// NewRestRequest creates an HTTP request against the API with 'rest' in path. The returned request
-// is assigned with ctx and has all necessary headers set (auth, user agent, etc.).
+// Credentials from client configuration are added to the JSON body of endpoints accepting them
+// unless it carries its own.
 func (c *Client) NewRequest(ctx context.Context, method, urlPath string, data interface{}) (*http.Request, error) {
 	return NewRequest(c, ctx, method, urlPath, data)
 }
@@ -140,17 +213,16 @@ var NewRequest = func(c *Client, ctx context.Context, method, urlPath string, da
 		return nil, err
 	}
 
-	uri := APIURI + urlPath
-
-	if c.Config.SandboxMode {
-		uri = APISandboxURI + urlPath
-	}
-
-	if c.Config.endpoint != "" {
-		uri = c.Config.endpoint + urlPath
+	uri, err := c.url(urlPath)
+	if err != nil {
+		return nil, err
 	}
 
 	reqBodyData, _ := json.Marshal(data)
+	reqBodyData, err = c.addCredentials(urlPath, reqBodyData)
+	if err != nil {
+		return nil, err
+	}
 
 	req, err := http.NewRequest(method, uri, bytes.NewReader(reqBodyData))
 
@@ -160,69 +232,80 @@ var NewRequest = func(c *Client, ctx context.Context, method, urlPath string, da
 
 	req.Header.Set("Cache-Control", "no-cache")
 	req.Header.Set("Content-Type", "application/json")
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
 
-	req = req.WithContext(ctx)
+	req = req.WithContext(withEndpoint(ctx, urlPath))
 
 	return req, nil
 }
 
-var reader = func(r io.Reader) ([]byte, error) {
-	return io.ReadAll(r)
+// credentialEndpoints are JSON endpoints authenticated with userName/password or token.
+// Apple, Google and Samsung Pay payments are authenticated with merchant and get no credentials.
+var credentialEndpoints = map[string]bool{
+	endpoints.MirPay:          true,
+	endpoints.MirPayDirect:    true,
+	endpoints.ExternalReceipt: true,
 }
 
-// Do perform an HTTP request against the API.
-func (c *Client) Do(r *http.Request, v interface{}) (*http.Response, error) {
-	resp, err := c.httpClient.Do(r)
-	if err != nil {
-		return nil, err
+// addCredentials adds credentials from client configuration to a JSON object body of an endpoint
+// accepting them, unless the body carries its own, the same way NewRestRequest does for form bodies.
+func (c *Client) addCredentials(urlPath string, body []byte) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(body, &fields) != nil || fields == nil {
+		return body, nil
 	}
 
-	body, err := reader(resp.Body)
-	if err != nil {
-		resp.Body.Close()
-		return resp, err
+	var credentials struct {
+		UserName string `json:"userName"`
+		Password string `json:"password"`
+		Token    string `json:"token"`
 	}
-	resp.Body.Close()
-	resp.Body = io.NopCloser(bytes.NewReader(body))
-
-	if resp.StatusCode >= 400 && resp.StatusCode <= 599 {
-		err = errorFromResponse(resp, body)
-		if err == nil {
-			err = fmt.Errorf("sberbank server responded with status code %d", resp.StatusCode)
-		}
-		return resp, err
+	_ = json.Unmarshal(body, &credentials)
+	if credentials.Token != "" && (credentials.UserName != "" || credentials.Password != "") {
+		return nil, ErrMixedCredentials
 	}
-	if v != nil {
-		if w, ok := v.(io.Writer); ok {
-			_, err = io.Copy(w, bytes.NewReader(body))
-		} else {
-			err = json.Unmarshal(body, v)
-		}
+	if !credentialEndpoints[urlPath] || credentials.Token != "" || credentials.UserName != "" || credentials.Password != "" {
+		return body, nil
+	}
+
+	delete(fields, "userName")
+	delete(fields, "password")
+	delete(fields, "token")
+	if c.Config.token != "" {
+		fields["token"], _ = json.Marshal(c.Config.token)
+	} else {
+		fields["userName"], _ = json.Marshal(c.Config.UserName)
+		fields["password"], _ = json.Marshal(c.Config.Password)
 	}
 
-	return resp, err
+	return json.Marshal(fields)
 }
 
-func errorFromResponse(resp *http.Response, body []byte) error {
-	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
-		return nil
-	}
+var reader = func(r io.Reader) ([]byte, error) {
+	return io.ReadAll(r)
+}
 
-	var respBody schema.Response
-	if err := json.Unmarshal(body, &respBody); err != nil {
-		return nil
-	}
-	if respBody.ErrorCode == 0 && respBody.ErrorMessage == "" {
-		return nil
-	}
-	return fmt.Errorf("%s", respBody.ErrorMessage)
+// hasCredentials reports whether request data carries own credentials,
+// which are sent instead of the ones from client configuration.
+func hasCredentials(data map[string]string) bool {
+	return data["token"] != "" || data["userName"] != "" || data["password"] != ""
 }
 
 func (c *ClientConfig) validate() error {
-	if (c.Password == "" && c.UserName == "") || c.token != "" {
+	if c.Password == "" && c.UserName == "" && c.token == "" {
 		return errors.New("Login/Password or Token can't be empty")
 	}
 
+	if c.token != "" && (c.Password != "" || c.UserName != "") {
+		return ErrMixedCredentials
+	}
+
+	if c.endpoint == "" {
+		return nil
+	}
+
 	if _, err := url.ParseRequestURI(c.endpoint); err != nil {
 		return fmt.Errorf("unable to parse URL: %v", err)
 	}
@@ -230,20 +313,72 @@ func (c *ClientConfig) validate() error {
 	return nil
 }
 
+// NewClient creates a self-contained client for the given configuration.
+// The configuration is copied, so clients built from different configs
+// can be used side by side in one process, independently of SetConfig.
+func NewClient(config ClientConfig, options ...ClientOption) (*Client, error) {
+	client := newAPI(&config, options...)
+	if client.err != nil {
+		return nil, client.err
+	}
+
+	if err := client.Config.validate(); err != nil {
+		return nil, err
+	}
+	if _, err := client.baseURL(); err != nil {
+		return nil, err
+	}
+
+	return client, nil
+}
+
 // newAPI creates a new client.
 func newAPI(cfg *ClientConfig, options ...ClientOption) *Client {
 	client := &Client{
 		Config:     cfg,
-		httpClient: &http.Client{},
+		httpClient: &http.Client{Timeout: DefaultTimeout},
 	}
 
+	client.apply(options...)
+
+	return client
+}
+
+// apply applies options to the client and prepares its http.Client.
+// Configuration errors are kept in the client and returned by NewClient and Do.
+func (c *Client) apply(options ...ClientOption) {
 	for _, option := range options {
-		option(client)
+		option(c)
 	}
 
-	return client
+	if err := c.prepareTransport(); err != nil {
+		c.setErr(err)
+	}
+
+	if c.timeout > 0 && c.httpClient.Timeout != c.timeout {
+		httpClient := *c.httpClient
+		httpClient.Timeout = c.timeout
+		c.httpClient = &httpClient
+	}
+}
+
+// clone returns a copy of the client with own configuration.
+func (c *Client) clone() *Client {
+	config := *c.Config
+	client := *c
+	client.Config = &config
+
+	return &client
 }
 
+var (
+	defaultMu     sync.RWMutex
+	defaultClient = newAPI(&ClientConfig{})
+)
+
+// GetAPI returns the default client used by package-level helpers of
+// the sub-packages. It is configured with SetConfig.
+// Options, if passed, are applied to a copy of the default client.
 func GetAPI(options ...ClientOption) API {
 	var api API
 
@@ -255,11 +390,25 @@ func GetAPI(options ...ClientOption) API {
 		return api
 	}
 
-	return newAPI(&cfg, options...)
+	defaultMu.RLock()
+	client := defaultClient
+	defaultMu.RUnlock()
+
+	if len(options) == 0 {
+		return client
+	}
+
+	client = client.clone()
+	client.apply(options...)
+
+	return client
 }
 
-var cfg ClientConfig
+// SetConfig sets configuration and options of the default client returned by GetAPI.
+func SetConfig(config ClientConfig, options ...ClientOption) {
+	client := newAPI(&config, options...)
 
-func SetConfig(config ClientConfig) {
-	cfg = config
+	defaultMu.Lock()
+	defaultClient = client
+	defaultMu.Unlock()
 }
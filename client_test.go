@@ -10,6 +10,7 @@ import (
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/helios-ag/sberbank-acquiring-go/currency"
 	"github.com/helios-ag/sberbank-acquiring-go/endpoints"
@@ -29,9 +30,10 @@ func TestNewClient(t *testing.T) {
 			SessionTimeoutSecs: 1200,
 			SandboxMode:        true,
 		}
-		WithEndpoint("http://api-sberbank///")
-		if strings.HasSuffix(cfg.endpoint, "/") {
-			t.Fatalf("endpoint has trailing slashes: %q", cfg.endpoint)
+		client, err := NewClient(cfg, WithEndpoint("http://api-sberbank///"))
+		Expect(err).ToNot(HaveOccurred())
+		if strings.HasSuffix(client.Config.endpoint, "/") {
+			t.Fatalf("endpoint has trailing slashes: %q", client.Config.endpoint)
 		}
 	})
 	t.Run("Test getting error response", func(t *testing.T) {
@@ -51,12 +53,378 @@ func TestNewClient(t *testing.T) {
 	})
 }
 
+func TestNewClientInstances(t *testing.T) {
+	RegisterTestingT(t)
+	t.Run("Clients keep own configuration", func(t *testing.T) {
+		first, err := NewClient(ClientConfig{UserName: "first-api", Password: "first", endpoint: "https://first.example"})
+		Expect(err).ToNot(HaveOccurred())
+		second, err := NewClient(ClientConfig{UserName: "second-api", Password: "second", endpoint: "https://second.example"})
+		Expect(err).ToNot(HaveOccurred())
+
+		SetConfig(ClientConfig{UserName: "default-api", Password: "default"})
+
+		ctx := context.Background()
+		req, err := first.NewRestRequest(ctx, http.MethodPost, endpoints.Register, nil, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(req.URL.Host).To(Equal("first.example"))
+		Expect(req.ParseForm()).To(Succeed())
+		Expect(req.PostForm.Get("userName")).To(Equal("first-api"))
+
+		req, err = second.NewRestRequest(ctx, http.MethodPost, endpoints.Register, nil, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(req.URL.Host).To(Equal("second.example"))
+		Expect(req.ParseForm()).To(Succeed())
+		Expect(req.PostForm.Get("userName")).To(Equal("second-api"))
+	})
+
+	t.Run("Config is copied", func(t *testing.T) {
+		config := ClientConfig{UserName: "test-api", Password: "test"}
+		client, err := NewClient(config)
+		Expect(err).ToNot(HaveOccurred())
+
+		config.UserName = "changed"
+		Expect(client.Config.UserName).To(Equal("test-api"))
+	})
+
+	t.Run("Invalid config is rejected", func(t *testing.T) {
+		_, err := NewClient(ClientConfig{})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Login/Password or Token can't be empty"))
+
+		_, err = NewClient(ClientConfig{UserName: "test-api", Password: "test", endpoint: "http//google.com"})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("unable to parse URL"))
+	})
+}
+
+type headerTransport struct {
+	calls int
+}
+
+func (h *headerTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	h.calls++
+	r.Header.Set("X-Custom-Transport", "yes")
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestClientOptions(t *testing.T) {
+	RegisterTestingT(t)
+	cfg := ClientConfig{
+		UserName: "test-api",
+		Password: "test",
+		Currency: currency.RUB,
+	}
+	ctx := context.Background()
+
+	t.Run("WithEndpoint", func(t *testing.T) {
+		testServer := server.NewServer()
+		defer testServer.Teardown()
+
+		called := false
+		testServer.Mux.HandleFunc(endpoints.Register, func(w http.ResponseWriter, r *http.Request) {
+			called = true
+			json.NewEncoder(w).Encode(schema.OrderResponse{})
+		})
+
+		client, err := NewClient(cfg, WithEndpoint(testServer.URL+"/"))
+		Expect(err).ToNot(HaveOccurred())
+		req, err := client.NewRestRequest(ctx, http.MethodPost, endpoints.Register, nil, nil)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = client.Do(req, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(called).To(BeTrue())
+	})
+
+	t.Run("WithToken", func(t *testing.T) {
+		client := newAPI(&ClientConfig{UserName: "test-api", Password: "test"}, WithToken("secret-token"))
+		Expect(client.Config.token).To(Equal("secret-token"))
+		Expect(client.Config.UserName).To(BeEmpty())
+		Expect(client.Config.Password).To(BeEmpty())
+	})
+
+	t.Run("WithHTTPClient", func(t *testing.T) {
+		testServer := server.NewServer()
+		defer testServer.Teardown()
+
+		testServer.Mux.HandleFunc(endpoints.Register, func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.Header.Get("X-Custom-Transport")).To(Equal("yes"))
+			json.NewEncoder(w).Encode(schema.OrderResponse{})
+		})
+
+		transport := &headerTransport{}
+		client, err := NewClient(cfg, WithEndpoint(testServer.URL), WithHTTPClient(&http.Client{Transport: transport}))
+		Expect(err).ToNot(HaveOccurred())
+		req, err := client.NewRestRequest(ctx, http.MethodPost, endpoints.Register, nil, nil)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = client.Do(req, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(transport.calls).To(Equal(1))
+	})
+
+	t.Run("WithTimeout", func(t *testing.T) {
+		testServer := server.NewServer()
+		defer testServer.Teardown()
+
+		testServer.Mux.HandleFunc(endpoints.Register, func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(200 * time.Millisecond)
+			json.NewEncoder(w).Encode(schema.OrderResponse{})
+		})
+
+		httpClient := &http.Client{}
+		client, err := NewClient(cfg, WithEndpoint(testServer.URL), WithHTTPClient(httpClient), WithTimeout(20*time.Millisecond))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(httpClient.Timeout).To(BeZero())
+
+		req, err := client.NewRestRequest(ctx, http.MethodPost, endpoints.Register, nil, nil)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = client.Do(req, nil)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Client.Timeout exceeded"))
+	})
+
+	t.Run("WithUserAgent", func(t *testing.T) {
+		testServer := server.NewServer()
+		defer testServer.Teardown()
+
+		testServer.Mux.HandleFunc(endpoints.Register, func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.UserAgent()).To(Equal("shop/1.0"))
+			json.NewEncoder(w).Encode(schema.OrderResponse{})
+		})
+		testServer.Mux.HandleFunc(endpoints.ApplePay, func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.UserAgent()).To(Equal("shop/1.0"))
+			json.NewEncoder(w).Encode(schema.ApplePaymentResponse{Success: true})
+		})
+
+		client, err := NewClient(cfg, WithEndpoint(testServer.URL), WithUserAgent("shop/1.0"))
+		Expect(err).ToNot(HaveOccurred())
+
+		req, err := client.NewRestRequest(ctx, http.MethodPost, endpoints.Register, nil, nil)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = client.Do(req, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		req, err = client.NewRequest(ctx, http.MethodPost, endpoints.ApplePay, nil)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = client.Do(req, nil)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	t.Run("WithLanguage", func(t *testing.T) {
+		testServer := server.NewServer()
+		defer testServer.Teardown()
+
+		testServer.Mux.HandleFunc(endpoints.Register, func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.ParseForm()).To(Succeed())
+			Expect(r.PostForm.Get("language")).To(Equal("en"))
+			json.NewEncoder(w).Encode(schema.OrderResponse{})
+		})
+
+		client, err := NewClient(cfg, WithEndpoint(testServer.URL), WithLanguage("en"))
+		Expect(err).ToNot(HaveOccurred())
+		req, err := client.NewRestRequest(ctx, http.MethodPost, endpoints.Register, nil, nil)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = client.Do(req, nil)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	t.Run("WithSandbox", func(t *testing.T) {
+		client, err := NewClient(cfg, WithSandbox(true))
+		Expect(err).ToNot(HaveOccurred())
+		req, err := client.NewRestRequest(ctx, http.MethodPost, endpoints.Register, nil, nil)
+		Expect(err).ToNot(HaveOccurred())
+		u, _ := url.Parse(APISandboxURI)
+		Expect(req.URL.Host).To(Equal(u.Host))
+
+		client, err = NewClient(cfg, WithSandbox(false))
+		Expect(err).ToNot(HaveOccurred())
+		req, err = client.NewRestRequest(ctx, http.MethodPost, endpoints.Register, nil, nil)
+		Expect(err).ToNot(HaveOccurred())
+		u, _ = url.Parse(APIURI)
+		Expect(req.URL.Host).To(Equal(u.Host))
+	})
+
+	t.Run("GetAPI options do not change default client", func(t *testing.T) {
+		SetConfig(cfg, WithEndpoint("https://default.example"))
+
+		api := GetAPI(WithEndpoint("https://other.example"))
+		req, err := api.NewRestRequest(ctx, http.MethodPost, endpoints.Register, nil, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(req.URL.Host).To(Equal("other.example"))
+
+		req, err = GetAPI().NewRestRequest(ctx, http.MethodPost, endpoints.Register, nil, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(req.URL.Host).To(Equal("default.example"))
+	})
+}
+
+func TestTokenAuthentication(t *testing.T) {
+	RegisterTestingT(t)
+	ctx := context.Background()
+
+	sendForm := func(client *Client, data map[string]string) url.Values {
+		testServer := server.NewServer()
+		defer testServer.Teardown()
+
+		var form url.Values
+		testServer.Mux.HandleFunc(endpoints.Deposit, func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.ParseForm()).To(Succeed())
+			form = r.PostForm
+			json.NewEncoder(w).Encode(schema.Response{})
+		})
+
+		client.Config.endpoint = testServer.URL
+		req, err := client.NewRestRequest(ctx, http.MethodPost, endpoints.Deposit, data, nil)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = client.Do(req, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		return form
+	}
+
+	sendJSON := func(client *Client, endpoint string, data interface{}) map[string]interface{} {
+		testServer := server.NewServer()
+		defer testServer.Teardown()
+
+		var body map[string]interface{}
+		testServer.Mux.HandleFunc(endpoint, func(w http.ResponseWriter, r *http.Request) {
+			Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+			json.NewEncoder(w).Encode(schema.Response{})
+		})
+
+		client.Config.endpoint = testServer.URL
+		req, err := client.NewRequest(ctx, http.MethodPost, endpoint, data)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = client.Do(req, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		return body
+	}
+
+	keys := func(form url.Values) []string {
+		var result []string
+		for key := range form {
+			result = append(result, key)
+		}
+		return result
+	}
+
+	t.Run("Login and password are sent", func(t *testing.T) {
+		client, err := NewClient(ClientConfig{UserName: "test-api", Password: "test"})
+		Expect(err).ToNot(HaveOccurred())
+
+		form := sendForm(client, map[string]string{"orderId": "123"})
+		Expect(keys(form)).To(ConsistOf("userName", "password", "orderId"))
+		Expect(form.Get("userName")).To(Equal("test-api"))
+		Expect(form.Get("password")).To(Equal("test"))
+	})
+
+	t.Run("Only token is sent", func(t *testing.T) {
+		client, err := NewClient(ClientConfig{}, WithToken("merchant-token"))
+		Expect(err).ToNot(HaveOccurred())
+
+		form := sendForm(client, map[string]string{"orderId": "123"})
+		Expect(keys(form)).To(ConsistOf("token", "orderId"))
+		Expect(form.Get("token")).To(Equal("merchant-token"))
+	})
+
+	t.Run("Request credentials replace client token", func(t *testing.T) {
+		client, err := NewClient(ClientConfig{}, WithToken("merchant-token"))
+		Expect(err).ToNot(HaveOccurred())
+
+		form := sendForm(client, map[string]string{"userName": "refund-api", "password": "refund"})
+		Expect(keys(form)).To(ConsistOf("userName", "password"))
+		Expect(form.Get("userName")).To(Equal("refund-api"))
+	})
+
+	t.Run("Request credentials replace client login", func(t *testing.T) {
+		client, err := NewClient(ClientConfig{UserName: "test-api", Password: "test"})
+		Expect(err).ToNot(HaveOccurred())
+
+		form := sendForm(client, map[string]string{"token": "request-token"})
+		Expect(keys(form)).To(ConsistOf("token"))
+	})
+
+	t.Run("Both credential kinds are refused", func(t *testing.T) {
+		client, err := NewClient(ClientConfig{UserName: "test-api", Password: "test"})
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = client.NewRestRequest(ctx, http.MethodPost, endpoints.Deposit, map[string]string{
+			"token":    "request-token",
+			"password": "test",
+		}, nil)
+		Expect(err).To(MatchError(ErrMixedCredentials))
+
+		_, err = client.NewRequest(ctx, http.MethodPost, endpoints.MirPay, map[string]string{
+			"token":    "request-token",
+			"userName": "test-api",
+		})
+		Expect(err).To(MatchError(ErrMixedCredentials))
+
+		_, err = NewClient(ClientConfig{UserName: "test-api"}, WithToken("token"), WithEndpoint("https://google.com"))
+		Expect(err).ToNot(HaveOccurred())
+
+		config := ClientConfig{UserName: "test-api", token: "token"}
+		Expect(config.validate()).To(MatchError(ErrMixedCredentials))
+	})
+
+	t.Run("Token is sent with Mir Pay requests", func(t *testing.T) {
+		client, err := NewClient(ClientConfig{}, WithToken("merchant-token"))
+		Expect(err).ToNot(HaveOccurred())
+
+		body := sendJSON(client, endpoints.MirPay, map[string]string{"merchant": "shop"})
+		Expect(body).To(Equal(map[string]interface{}{
+			"merchant": "shop",
+			"token":    "merchant-token",
+		}))
+	})
+
+	t.Run("Login and password are sent with Mir Pay requests", func(t *testing.T) {
+		client, err := NewClient(ClientConfig{UserName: "test-api", Password: "test"})
+		Expect(err).ToNot(HaveOccurred())
+
+		body := sendJSON(client, endpoints.MirPay, map[string]string{"merchant": "shop", "token": ""})
+		Expect(body).To(Equal(map[string]interface{}{
+			"merchant": "shop",
+			"userName": "test-api",
+			"password": "test",
+		}))
+	})
+
+	t.Run("Wallet payments are sent without credentials", func(t *testing.T) {
+		client, err := NewClient(ClientConfig{UserName: "test-api", Password: "test"})
+		Expect(err).ToNot(HaveOccurred())
+
+		for _, endpoint := range []string{endpoints.ApplePay, endpoints.GooglePay, endpoints.SamsungPay, endpoints.SamsungWebPay} {
+			body := sendJSON(client, endpoint, map[string]string{"merchant": "shop"})
+			Expect(body).To(Equal(map[string]interface{}{"merchant": "shop"}), endpoint)
+		}
+
+		client, err = NewClient(ClientConfig{}, WithToken("merchant-token"))
+		Expect(err).ToNot(HaveOccurred())
+
+		body := sendJSON(client, endpoints.GooglePay, map[string]string{"merchant": "shop"})
+		Expect(body).ToNot(HaveKey("token"))
+	})
+
+	t.Run("Mobile request credentials replace client token", func(t *testing.T) {
+		client, err := NewClient(ClientConfig{}, WithToken("merchant-token"))
+		Expect(err).ToNot(HaveOccurred())
+
+		body := sendJSON(client, endpoints.MirPay, map[string]string{"merchant": "shop", "userName": "pay-api", "password": "pay"})
+		Expect(body).To(Equal(map[string]interface{}{
+			"merchant": "shop",
+			"userName": "pay-api",
+			"password": "pay",
+		}))
+	})
+}
+
 func TestClientDo(t *testing.T) {
 	RegisterTestingT(t)
 	t.Run("Test client do with external api", func(t *testing.T) {
 		testServer := server.NewServer()
 		defer testServer.Teardown()
-		WithEndpoint(testServer.URL)
+		SetConfig(ClientConfig{UserName: "test-api", Password: "test"}, WithEndpoint(testServer.URL))
 
 		testServer.Mux.HandleFunc(endpoints.Register, func(w http.ResponseWriter, r *http.Request) {
 			json.NewEncoder(w).Encode(schema.Response{
@@ -83,7 +451,7 @@ func TestClientDo(t *testing.T) {
 	t.Run("Test response body decode", func(t *testing.T) {
 		testServer := server.NewServer()
 		defer testServer.Teardown()
-		WithEndpoint(testServer.URL)
+		SetConfig(ClientConfig{UserName: "test-api", Password: "test"}, WithEndpoint(testServer.URL))
 		testServer.Mux.HandleFunc(endpoints.Register, func(w http.ResponseWriter, r *http.Request) {
 			json.NewEncoder(w).Encode(schema.Response{
 				ErrorMessage: "Доступ запрещён.",
@@ -93,14 +461,16 @@ func TestClientDo(t *testing.T) {
 
 		ctx := context.Background()
 		request, _ := GetAPI().NewRestRequest(ctx, http.MethodGet, endpoints.Register, nil, nil)
-		_, err := GetAPI().Do(request, nil)
-		Expect(err).ToNot(HaveOccurred())
+		var response schema.Response
+		_, err := GetAPI().Do(request, &response)
+		Expect(err).To(MatchError(ErrAccessDenied))
+		Expect(response.ErrorCode).To(Equal(5))
 	})
 
 	t.Run("Test Bad Response Code", func(t *testing.T) {
 		testServer := server.NewServer()
 		defer testServer.Teardown()
-		WithEndpoint(testServer.URL)
+		SetConfig(ClientConfig{UserName: "test-api", Password: "test"}, WithEndpoint(testServer.URL))
 
 		testServer.Mux.HandleFunc(endpoints.Register, func(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "Bad Request", http.StatusBadRequest)
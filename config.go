@@ -0,0 +1,380 @@
+package sberbank_acquiring_go
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// Environment variables read by LoadConfig
+const (
+	EnvConfig         = "SBERACQ_CONFIG"
+	EnvProfile        = "SBERACQ_PROFILE"
+	EnvUserName       = "SBERACQ_USERNAME"
+	EnvPassword       = "SBERACQ_PASSWORD"
+	EnvPasswordFile   = "SBERACQ_PASSWORD_FILE"
+	EnvToken          = "SBERACQ_TOKEN"
+	EnvTokenFile      = "SBERACQ_TOKEN_FILE"
+	EnvSandbox        = "SBERACQ_SANDBOX"
+	EnvEndpoint       = "SBERACQ_ENDPOINT"
+	EnvGateway        = "SBERACQ_GATEWAY"
+	EnvLanguage       = "SBERACQ_LANGUAGE"
+	EnvCurrency       = "SBERACQ_CURRENCY"
+	EnvSessionTimeout = "SBERACQ_SESSION_TIMEOUT"
+	EnvTimeout        = "SBERACQ_TIMEOUT"
+	EnvCertFile       = "SBERACQ_CERT_FILE"
+	EnvKeyFile        = "SBERACQ_KEY_FILE"
+)
+
+// DefaultProfile is the profile loaded from the config file when none is requested.
+const DefaultProfile = "default"
+
+// ErrSandboxMismatch is returned by LoadConfig when sandbox credentials would be sent
+// to the production URL of a gateway, or production credentials to the sandbox one.
+var ErrSandboxMismatch = errors.New("sandbox mode doesn't match the gateway URL")
+
+// ConfigSource tells LoadConfig where to read the configuration from.
+type ConfigSource struct {
+	// File is a YAML (.yaml, .yml) or JSON (.json) file with profiles, SBERACQ_CONFIG by default.
+	// Without a file the configuration is read from the environment only.
+	File string
+	// Profile is the name of the profile in the file, SBERACQ_PROFILE or DefaultProfile by default
+	Profile string
+	// LookupEnv reads environment variables, os.LookupEnv by default
+	LookupEnv func(key string) (string, bool)
+}
+
+// profileSettings is a profile in the config file.
+type profileSettings struct {
+	UserName       string `yaml:"username" json:"username"`
+	Password       string `yaml:"password" json:"password"`
+	PasswordFile   string `yaml:"password_file" json:"password_file"`
+	Token          string `yaml:"token" json:"token"`
+	TokenFile      string `yaml:"token_file" json:"token_file"`
+	Sandbox        bool   `yaml:"sandbox" json:"sandbox"`
+	Endpoint       string `yaml:"endpoint" json:"endpoint"`
+	Gateway        string `yaml:"gateway" json:"gateway"`
+	Language       string `yaml:"language" json:"language"`
+	Currency       int    `yaml:"currency" json:"currency"`
+	SessionTimeout int    `yaml:"session_timeout" json:"session_timeout"`
+	Timeout        string `yaml:"timeout" json:"timeout"`
+	CertFile       string `yaml:"cert_file" json:"cert_file"`
+	KeyFile        string `yaml:"key_file" json:"key_file"`
+}
+
+// LoadConfig loads the profile from the config file and overrides it with environment variables.
+// Credentials set in the environment replace the ones of the file as a whole.
+// Secrets may be read from files, e.g. Docker or Kubernetes secrets, with password_file
+// and token_file (SBERACQ_PASSWORD_FILE, SBERACQ_TOKEN_FILE).
+//
+//	profile, err := acquiring.LoadConfig(acquiring.ConfigSource{})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	client, err := acquiring.NewClient(profile.Config, profile.Options...)
+func LoadConfig(source ConfigSource) (Profile, error) {
+	lookupEnv := source.LookupEnv
+	if lookupEnv == nil {
+		lookupEnv = os.LookupEnv
+	}
+	getenv := func(key string) string {
+		value, _ := lookupEnv(key)
+		return value
+	}
+
+	file := source.File
+	if file == "" {
+		file = getenv(EnvConfig)
+	}
+	name := source.Profile
+	if name == "" {
+		name = getenv(EnvProfile)
+	}
+
+	var settings profileSettings
+	switch {
+	case file != "":
+		if name == "" {
+			name = DefaultProfile
+		}
+		profiles, err := readProfiles(file)
+		if err != nil {
+			return Profile{}, err
+		}
+		var ok bool
+		if settings, ok = profiles[name]; !ok {
+			return Profile{}, fmt.Errorf("%w: %s in %s", ErrProfileNotFound, name, file)
+		}
+	case name != "":
+		return Profile{}, fmt.Errorf("profile %s is requested without config file, set %s", name, EnvConfig)
+	default:
+		name = "environment"
+	}
+
+	if err := settings.override(getenv); err != nil {
+		return Profile{}, fmt.Errorf("invalid profile %s: %w", name, err)
+	}
+
+	profile, err := settings.profile()
+	if err != nil {
+		return Profile{}, fmt.Errorf("invalid profile %s: %w", name, err)
+	}
+
+	return profile, nil
+}
+
+// LoadProfiles loads all profiles of the config file, e.g. for ClientPool.
+// Environment variables are not applied.
+func LoadProfiles(file string) (Profiles, error) {
+	settings, err := readProfiles(file)
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := make(Profiles, len(settings))
+	for name, s := range settings {
+		profile, err := s.profile()
+		if err != nil {
+			return nil, fmt.Errorf("invalid profile %s: %w", name, err)
+		}
+		profiles[name] = profile
+	}
+
+	return profiles, nil
+}
+
+// readProfiles decodes the config file, unknown fields are rejected.
+func readProfiles(file string) (map[string]profileSettings, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("reading config: %w", err)
+	}
+
+	var config struct {
+		Profiles map[string]profileSettings `yaml:"profiles" json:"profiles"`
+	}
+
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(&config)
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&config)
+	default:
+		return nil, fmt.Errorf("config %s: unsupported format, use .yaml, .yml or .json", file)
+	}
+
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("config %s: %w", file, err)
+	}
+
+	if len(config.Profiles) == 0 {
+		return nil, fmt.Errorf("config %s: no profiles", file)
+	}
+
+	return config.Profiles, nil
+}
+
+// override applies environment variables to the settings.
+func (s *profileSettings) override(getenv func(key string) string) error {
+	if getenv(EnvUserName) != "" || getenv(EnvPassword) != "" || getenv(EnvPasswordFile) != "" ||
+		getenv(EnvToken) != "" || getenv(EnvTokenFile) != "" {
+		s.UserName = getenv(EnvUserName)
+		s.Password = getenv(EnvPassword)
+		s.PasswordFile = getenv(EnvPasswordFile)
+		s.Token = getenv(EnvToken)
+		s.TokenFile = getenv(EnvTokenFile)
+	}
+
+	strs := map[string]*string{
+		EnvEndpoint: &s.Endpoint,
+		EnvGateway:  &s.Gateway,
+		EnvLanguage: &s.Language,
+		EnvTimeout:  &s.Timeout,
+		EnvCertFile: &s.CertFile,
+		EnvKeyFile:  &s.KeyFile,
+	}
+	for key, field := range strs {
+		if value := getenv(key); value != "" {
+			*field = value
+		}
+	}
+
+	var errs []error
+	if value := getenv(EnvSandbox); value != "" {
+		sandbox, err := strconv.ParseBool(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s must be true or false, got %q", EnvSandbox, value))
+		}
+		s.Sandbox = sandbox
+	}
+
+	ints := []struct {
+		key   string
+		field *int
+	}{
+		{EnvCurrency, &s.Currency},
+		{EnvSessionTimeout, &s.SessionTimeout},
+	}
+	for _, i := range ints {
+		key, field := i.key, i.field
+		value := getenv(key)
+		if value == "" {
+			continue
+		}
+		number, err := strconv.Atoi(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s must be a number, got %q", key, value))
+		}
+		*field = number
+	}
+
+	return errors.Join(errs...)
+}
+
+// profile validates the settings and reads secrets from files.
+func (s profileSettings) profile() (Profile, error) {
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	password, passwordErr := secret("password", s.Password, s.PasswordFile)
+	token, tokenErr := secret("token", s.Token, s.TokenFile)
+
+	switch {
+	case passwordErr != nil || tokenErr != nil:
+		errs = append(errs, passwordErr, tokenErr)
+	case token != "" && (s.UserName != "" || password != ""):
+		errs = append(errs, ErrMixedCredentials)
+	case token == "" && s.UserName == "" && password == "":
+		fail("username and password or token are required")
+	case token == "" && (s.UserName == "" || password == ""):
+		fail("username and password are required together")
+	}
+
+	if s.Language != "" && (len(s.Language) != 2 || strings.ToLower(s.Language) != s.Language) {
+		fail("language must be a two-letter ISO 639-1 code, got %q", s.Language)
+	}
+	if s.Currency < 0 || s.Currency > 999 {
+		fail("currency must be a numeric ISO 4217 code, got %d", s.Currency)
+	}
+	if s.SessionTimeout < 0 {
+		fail("session_timeout must not be negative, got %d", s.SessionTimeout)
+	}
+
+	var timeout time.Duration
+	if s.Timeout != "" {
+		var err error
+		if timeout, err = time.ParseDuration(s.Timeout); err != nil || timeout <= 0 {
+			fail("timeout must be a positive duration like 30s, got %q", s.Timeout)
+		}
+	}
+
+	if (s.CertFile == "") != (s.KeyFile == "") {
+		fail("cert_file and key_file are required together")
+	}
+
+	gateway, _ := LookupGateway(GatewaySberbank)
+	if s.Gateway != "" {
+		var ok bool
+		if gateway, ok = LookupGateway(s.Gateway); !ok {
+			fail("unknown gateway %q, registered gateways are %s", s.Gateway, strings.Join(GatewayNames(), ", "))
+		}
+	}
+
+	endpoint := strings.TrimRight(s.Endpoint, "/")
+	if endpoint != "" {
+		if u, err := url.Parse(endpoint); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			fail("endpoint must be an absolute http(s) URL, got %q", s.Endpoint)
+		} else if err := checkSandbox(u, s.Sandbox); err != nil {
+			errs = append(errs, err)
+		}
+	} else if s.Sandbox && gateway.SandboxURL == "" {
+		fail("gateway %s has no sandbox URL, set endpoint", gateway.Name)
+	}
+
+	if len(errs) > 0 {
+		return Profile{}, errors.Join(errs...)
+	}
+
+	profile := Profile{
+		Config: ClientConfig{
+			UserName:           s.UserName,
+			Password:           password,
+			Currency:           s.Currency,
+			Language:           s.Language,
+			SessionTimeoutSecs: s.SessionTimeout,
+			endpoint:           endpoint,
+			token:              token,
+			SandboxMode:        s.Sandbox,
+		},
+	}
+	if s.Gateway != "" {
+		profile.Options = append(profile.Options, WithGateway(gateway))
+	}
+	if timeout > 0 {
+		profile.Options = append(profile.Options, WithTimeout(timeout))
+	}
+	if s.CertFile != "" {
+		profile.Options = append(profile.Options, WithClientCertificateFile(s.CertFile, s.KeyFile))
+	}
+
+	return profile, nil
+}
+
+// secret returns the value or the content of the file, they are mutually exclusive.
+func secret(name, value, file string) (string, error) {
+	if file == "" {
+		return value, nil
+	}
+	if value != "" {
+		return "", fmt.Errorf("%s and %s_file can't be used together", name, name)
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("reading %s: %w", name, err)
+	}
+
+	value = strings.TrimSpace(string(data))
+	if value == "" {
+		return "", fmt.Errorf("%s file %s is empty", name, file)
+	}
+
+	return value, nil
+}
+
+// checkSandbox guards against sandbox credentials sent to the production URL of a registered gateway
+// and production credentials sent to the sandbox one.
+func checkSandbox(endpoint *url.URL, sandbox bool) error {
+	sameHost := func(rawURL string) bool {
+		u, err := url.Parse(rawURL)
+		return err == nil && u.Host != "" && strings.EqualFold(u.Host, endpoint.Host)
+	}
+
+	for _, name := range GatewayNames() {
+		gateway, _ := LookupGateway(name)
+		switch {
+		case sandbox && sameHost(gateway.URL):
+			return fmt.Errorf("%w: sandbox credentials with production URL %s of %s", ErrSandboxMismatch, endpoint, name)
+		case !sandbox && sameHost(gateway.SandboxURL):
+			return fmt.Errorf("%w: production credentials with sandbox URL %s of %s", ErrSandboxMismatch, endpoint, name)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,194 @@
+package sberbank_acquiring_go
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestLoadConfig(t *testing.T) {
+	RegisterTestingT(t)
+
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		Expect(os.WriteFile(path, []byte(content), 0o600)).To(Succeed())
+		return path
+	}
+	env := func(vars map[string]string) func(string) (string, bool) {
+		return func(key string) (string, bool) {
+			value, ok := vars[key]
+			return value, ok
+		}
+	}
+
+	passwordFile := write("password", "secret\n")
+	config := write("acquiring.yaml", `
+profiles:
+  default:
+    username: shop-api
+    password_file: `+passwordFile+`
+    sandbox: true
+    language: ru
+    currency: 643
+    session_timeout: 1200
+    timeout: 15s
+  alfa:
+    token: alfa-token
+    gateway: alfabank
+`)
+
+	t.Run("YAML profile", func(t *testing.T) {
+		profile, err := LoadConfig(ConfigSource{File: config, LookupEnv: env(nil)})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(profile.Config).To(Equal(ClientConfig{
+			UserName:           "shop-api",
+			Password:           "secret",
+			Currency:           643,
+			Language:           "ru",
+			SessionTimeoutSecs: 1200,
+			SandboxMode:        true,
+		}))
+		Expect(profile.Options).To(HaveLen(1))
+
+		client, err := NewClient(profile.Config, profile.Options...)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(client.timeout).To(Equal(15 * time.Second))
+
+		profile, err = LoadConfig(ConfigSource{File: config, LookupEnv: env(map[string]string{EnvProfile: "alfa"})})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(profile.Config.token).To(Equal("alfa-token"))
+
+		client, err = NewClient(profile.Config, profile.Options...)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(client.gateway.Name).To(Equal(GatewayAlfaBank))
+	})
+
+	t.Run("JSON profile", func(t *testing.T) {
+		config := write("acquiring.json", `{"profiles": {"shop": {"username": "shop-api", "password": "secret", "endpoint": "http://localhost:8080/"}}}`)
+
+		profile, err := LoadConfig(ConfigSource{File: config, Profile: "shop", LookupEnv: env(nil)})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(profile.Config.endpoint).To(Equal("http://localhost:8080"))
+		Expect(profile.Options).To(BeEmpty())
+	})
+
+	t.Run("Environment overrides the file", func(t *testing.T) {
+		profile, err := LoadConfig(ConfigSource{LookupEnv: env(map[string]string{
+			EnvConfig:   config,
+			EnvToken:    "token",
+			EnvSandbox:  "false",
+			EnvLanguage: "en",
+		})})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(profile.Config.UserName).To(BeEmpty())
+		Expect(profile.Config.Password).To(BeEmpty())
+		Expect(profile.Config.token).To(Equal("token"))
+		Expect(profile.Config.SandboxMode).To(BeFalse())
+		Expect(profile.Config.Language).To(Equal("en"))
+		Expect(profile.Config.Currency).To(Equal(643))
+	})
+
+	t.Run("Environment only", func(t *testing.T) {
+		profile, err := LoadConfig(ConfigSource{LookupEnv: env(map[string]string{
+			EnvUserName:       "shop-api",
+			EnvPasswordFile:   passwordFile,
+			EnvSandbox:        "1",
+			EnvSessionTimeout: "600",
+		})})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(profile.Config.Password).To(Equal("secret"))
+		Expect(profile.Config.SandboxMode).To(BeTrue())
+		Expect(profile.Config.SessionTimeoutSecs).To(Equal(600))
+	})
+
+	t.Run("Sandbox credentials with production URL", func(t *testing.T) {
+		_, err := LoadConfig(ConfigSource{LookupEnv: env(map[string]string{
+			EnvToken:    "token",
+			EnvSandbox:  "true",
+			EnvEndpoint: "https://securepayments.sberbank.ru/",
+		})})
+		Expect(err).To(MatchError(ErrSandboxMismatch))
+		Expect(err).To(MatchError(ContainSubstring("sandbox credentials with production URL")))
+
+		_, err = LoadConfig(ConfigSource{LookupEnv: env(map[string]string{
+			EnvToken:    "token",
+			EnvEndpoint: "https://alfa.rbsuat.com",
+		})})
+		Expect(err).To(MatchError(ErrSandboxMismatch))
+		Expect(err).To(MatchError(ContainSubstring("production credentials with sandbox URL")))
+	})
+
+	t.Run("Validation errors", func(t *testing.T) {
+		errorOf := func(vars map[string]string) error {
+			_, err := LoadConfig(ConfigSource{LookupEnv: env(vars)})
+			Expect(err).To(HaveOccurred())
+			return err
+		}
+
+		Expect(errorOf(nil)).To(MatchError("invalid profile environment: username and password or token are required"))
+		Expect(errorOf(map[string]string{EnvUserName: "shop-api"})).To(MatchError(ContainSubstring("username and password are required together")))
+		Expect(errorOf(map[string]string{EnvToken: "token", EnvPassword: "secret"})).To(MatchError(ErrMixedCredentials))
+		Expect(errorOf(map[string]string{EnvToken: "token", EnvTokenFile: passwordFile})).To(MatchError(ContainSubstring("token and token_file can't be used together")))
+		Expect(errorOf(map[string]string{EnvToken: "token", EnvTokenFile: passwordFile})).ToNot(MatchError(ContainSubstring("are required")))
+		Expect(errorOf(map[string]string{EnvTokenFile: filepath.Join(dir, "missing")})).To(MatchError(os.ErrNotExist))
+		Expect(errorOf(map[string]string{EnvTokenFile: write("empty", "\n")})).To(MatchError(ContainSubstring("is empty")))
+		Expect(errorOf(map[string]string{EnvToken: "token", EnvSandbox: "yes"})).To(MatchError(ContainSubstring("SBERACQ_SANDBOX must be true or false")))
+		Expect(errorOf(map[string]string{EnvToken: "token", EnvCurrency: "RUB"})).To(MatchError(ContainSubstring("SBERACQ_CURRENCY must be a number")))
+		Expect(errorOf(map[string]string{EnvToken: "token", EnvLanguage: "russian"})).To(MatchError(ContainSubstring("language must be a two-letter")))
+		Expect(errorOf(map[string]string{EnvToken: "token", EnvTimeout: "15"})).To(MatchError(ContainSubstring("timeout must be a positive duration")))
+		Expect(errorOf(map[string]string{EnvToken: "token", EnvEndpoint: "localhost:8080"})).To(MatchError(ContainSubstring("endpoint must be an absolute http(s) URL")))
+		Expect(errorOf(map[string]string{EnvToken: "token", EnvGateway: "bank"})).To(MatchError(ContainSubstring(`unknown gateway "bank"`)))
+		Expect(errorOf(map[string]string{EnvToken: "token", EnvCertFile: "client.pem"})).To(MatchError(ContainSubstring("cert_file and key_file are required together")))
+		Expect(errorOf(map[string]string{EnvProfile: "shop"})).To(MatchError(ContainSubstring("set SBERACQ_CONFIG")))
+
+		errs := errorOf(map[string]string{EnvToken: "token", EnvLanguage: "russian", EnvCurrency: "-1"})
+		Expect(errs).To(MatchError(ContainSubstring("language")))
+		Expect(errs).To(MatchError(ContainSubstring("currency")))
+	})
+
+	t.Run("File errors", func(t *testing.T) {
+		errorOf := func(file, profile string) error {
+			_, err := LoadConfig(ConfigSource{File: file, Profile: profile, LookupEnv: env(nil)})
+			Expect(err).To(HaveOccurred())
+			return err
+		}
+
+		Expect(errorOf(config, "shop")).To(MatchError(ErrProfileNotFound))
+		Expect(errorOf(filepath.Join(dir, "missing.yaml"), "")).To(MatchError(os.ErrNotExist))
+		Expect(errorOf(write("acquiring.toml", ""), "")).To(MatchError(ContainSubstring("unsupported format")))
+		Expect(errorOf(write("empty.yaml", ""), "")).To(MatchError(ContainSubstring("no profiles")))
+		Expect(errorOf(write("typo.yaml", "profiles:\n  default:\n    user: shop-api\n"), "")).To(MatchError(ContainSubstring("field user not found")))
+		Expect(errorOf(write("typo.json", `{"profiles": {"default": {"pasword": "secret"}}}`), "")).To(MatchError(ContainSubstring(`unknown field "pasword"`)))
+	})
+}
+
+func TestLoadProfiles(t *testing.T) {
+	RegisterTestingT(t)
+
+	dir := t.TempDir()
+	config := filepath.Join(dir, "merchants.yml")
+	Expect(os.WriteFile(config, []byte(`
+profiles:
+  shop-1: {username: shop-1-api, password: secret-1}
+  shop-2: {token: token-2}
+`), 0o600)).To(Succeed())
+
+	profiles, err := LoadProfiles(config)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(profiles).To(HaveLen(2))
+	Expect(profiles["shop-1"].Config.Password).To(Equal("secret-1"))
+	Expect(profiles["shop-2"].Config.token).To(Equal("token-2"))
+
+	pool, err := NewClientPool(profiles)
+	Expect(err).ToNot(HaveOccurred())
+	_, err = pool.Client(t.Context(), "shop-2")
+	Expect(err).ToNot(HaveOccurred())
+
+	Expect(os.WriteFile(config, []byte("profiles:\n  shop-1: {username: shop-1-api}\n"), 0o600)).To(Succeed())
+	_, err = LoadProfiles(config)
+	Expect(err).To(MatchError(ContainSubstring("invalid profile shop-1: username and password are required together")))
+}
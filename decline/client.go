@@ -2,7 +2,6 @@ package bind
 
 import (
 	"context"
-	"encoding/json"
 	"net/http"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
@@ -15,20 +14,31 @@ type Client struct {
 	API acquiring.API
 }
 
+// NewClient creates a Client that sends requests through api,
+// usually a client created with acquiring.NewClient.
+func NewClient(api acquiring.API) Client {
+	return Client{API: api}
+}
+
 // DeclineRequest is used to make Decline method related requests
 type DeclineRequest struct {
-	Username      string
-	Password      string
-	MerchantLogin string
-	Language      string
-	OrderNumber   string
-	OrderId       string
+	Username      string `form:"userName,omitempty"`
+	Password      string `form:"password,omitempty"`
+	Token         string `form:"token,omitempty"`
+	MerchantLogin string `form:"merchantLogin,omitempty"`
+	Language      string `form:"language,omitempty"`
+	OrderNumber   string `form:"orderNumber,omitempty"`
+	OrderId       string `form:"orderId,omitempty"`
 }
 
 func (decline DeclineRequest) Validate() error {
+	if decline.Token != "" && (decline.Username != "" || decline.Password != "") {
+		return acquiring.ErrMixedCredentials
+	}
+
 	return validation.ValidateStruct(&decline,
-		validation.Field(&decline.Username, validation.Required),
-		validation.Field(&decline.Password, validation.Required),
+		validation.Field(&decline.Username, validation.When(decline.Password != "", validation.Required)),
+		validation.Field(&decline.Password, validation.When(decline.Username != "", validation.Required)),
 	)
 }
 
@@ -51,31 +61,13 @@ var decline = func(ctx context.Context, client Client, path string, decline Decl
 		return nil, nil, err
 	}
 
-	body := make(map[string]string)
-	body["userName"] = decline.Username
-	body["password"] = decline.Password
-	body["merchantLogin"] = decline.MerchantLogin
-	body["language"] = decline.Language
-	body["orderId"] = decline.OrderId
-	body["orderNumber"] = decline.OrderNumber
-
-	return client.decline(ctx, path, body)
+	return client.decline(ctx, path, decline)
 }
 
-func (c Client) decline(ctx context.Context, path string, body map[string]string) (*schema.DeclineResponse, *http.Response, error) {
-	var response schema.DeclineResponse
-	req, err := c.API.NewRestRequest(ctx, http.MethodPost, path, body, nil)
-
-	if err != nil {
-		return nil, nil, err
-	}
-	result, err := c.API.Do(req, &response)
-	if err != nil {
-		return nil, result, err
-	}
-	_ = json.NewDecoder(result.Body).Decode(&response)
+func (c Client) decline(ctx context.Context, path string, body DeclineRequest) (*schema.DeclineResponse, *http.Response, error) {
+	op := acquiring.Operation[DeclineRequest, schema.DeclineResponse]{Method: http.MethodPost, Path: path}
 
-	return &response, result, err
+	return acquiring.Call(ctx, c.API, op, body)
 }
 
 func getClient() Client {
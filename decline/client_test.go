@@ -3,7 +3,9 @@ package bind
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"net/url"
 	"testing"
 
 	acquiring "github.com/helios-ag/sberbank-acquiring-go"
@@ -24,8 +26,7 @@ func prepareClient(URL string) {
 		SessionTimeoutSecs: 1200,
 		SandboxMode:        true,
 	}
-	acquiring.SetConfig(cfg)
-	acquiring.WithEndpoint(URL)
+	acquiring.SetConfig(cfg, acquiring.WithEndpoint(URL))
 }
 
 func TestClient_Decline(t *testing.T) {
@@ -105,6 +106,53 @@ func TestClient_Decline(t *testing.T) {
 		})))
 	})
 
+	t.Run("Test Decline omits unset parameters", func(t *testing.T) {
+		testServer := server.NewServer()
+		defer testServer.Teardown()
+		prepareClient(testServer.URL)
+
+		var form url.Values
+		testServer.Mux.HandleFunc(endpoints.Decline, func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			form = r.PostForm
+			fmt.Fprint(w, `{"errorCode":"0"}`)
+		})
+
+		_, _, err := Decline(context.Background(), DeclineRequest{Username: "user", Password: "password", OrderId: "42"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(form.Get("userName")).To(Equal("user"))
+		Expect(form.Get("orderId")).To(Equal("42"))
+		Expect(form).ToNot(HaveKey("merchantLogin"))
+		Expect(form).ToNot(HaveKey("orderNumber"))
+	})
+
+	t.Run("Test Decline with client token", func(t *testing.T) {
+		testServer := server.NewServer()
+		defer testServer.Teardown()
+		acquiring.SetConfig(acquiring.ClientConfig{SandboxMode: true}, acquiring.WithEndpoint(testServer.URL), acquiring.WithToken("merchant-token"))
+
+		var form url.Values
+		testServer.Mux.HandleFunc(endpoints.Decline, func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			form = r.PostForm
+			fmt.Fprint(w, `{"errorCode":"0"}`)
+		})
+
+		_, _, err := Decline(context.Background(), DeclineRequest{OrderId: "42"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(form.Get("token")).To(Equal("merchant-token"))
+		Expect(form.Get("orderId")).To(Equal("42"))
+		Expect(form).ToNot(HaveKey("userName"))
+		Expect(form).ToNot(HaveKey("password"))
+
+		_, _, err = Decline(context.Background(), DeclineRequest{OrderId: "42", Username: "user", Password: "password"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(form.Get("userName")).To(Equal("user"))
+		Expect(form).ToNot(HaveKey("token"))
+
+		_, _, err = Decline(context.Background(), DeclineRequest{OrderId: "42", Token: "decline-token", Password: "password"})
+		Expect(err).To(MatchError(acquiring.ErrMixedCredentials))
+	})
 }
 
 func TestClient_ValidateBind(t *testing.T) {
@@ -0,0 +1,114 @@
+package sberbank_acquiring_go
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ErrDryRun is matched by the error returned instead of sending a request in dry-run mode.
+var ErrDryRun = errors.New("dry run, request is not sent")
+
+// PreparedRequest is a request built in dry-run mode, secrets in its body are redacted.
+type PreparedRequest struct {
+	// Endpoint is the API path of the request, e.g. endpoints.Register
+	Endpoint string
+	Method   string
+	URL      string
+	Header   http.Header
+	// Form is the body of a REST request
+	Form url.Values
+	// JSON is the body of a JSON request (mobile payments, external receipts)
+	JSON json.RawMessage
+}
+
+// Body returns the body as it would be sent, form encoded or JSON.
+func (p PreparedRequest) Body() string {
+	if p.JSON != nil {
+		return string(p.JSON)
+	}
+
+	return p.Form.Encode()
+}
+
+// DryRunError is returned by Client.Do in dry-run mode, it holds the request that would be sent.
+type DryRunError struct {
+	Request PreparedRequest
+}
+
+func (e *DryRunError) Error() string {
+	return "dry run, " + e.Request.Method + " " + e.Request.URL + " is not sent"
+}
+
+// Is reports whether target is ErrDryRun.
+func (e *DryRunError) Is(target error) bool {
+	return target == ErrDryRun
+}
+
+// WithDryRun configures a Client to build requests without sending them:
+// Do returns *DryRunError with the prepared request instead.
+func WithDryRun() ClientOption {
+	return func(c *Client) {
+		c.dryRun = true
+	}
+}
+
+type dryRunKey struct{}
+
+// DryRun calls fn in dry-run mode and returns the first request fn would send.
+// Validation errors of fn are returned as is:
+//
+//	prepared, err := acquiring.DryRun(ctx, func(ctx context.Context) error {
+//		_, _, err := orders.RegisterOrder(ctx, order)
+//		return err
+//	})
+//	fmt.Println(prepared.Method, prepared.URL, prepared.Body())
+func DryRun(ctx context.Context, fn func(ctx context.Context) error) (*PreparedRequest, error) {
+	err := fn(context.WithValue(ctx, dryRunKey{}, true))
+
+	var dryRunErr *DryRunError
+	switch {
+	case errors.As(err, &dryRunErr):
+		return &dryRunErr.Request, nil
+	case err != nil:
+		return nil, err
+	}
+
+	return nil, errors.New("dry run, no request is prepared")
+}
+
+// isDryRun reports whether the request must not be sent.
+func (c *Client) isDryRun(r *http.Request) bool {
+	return c.dryRun || r.Context().Value(dryRunKey{}) != nil
+}
+
+// prepare returns the request with redacted body.
+func prepare(r *http.Request) PreparedRequest {
+	prepared := PreparedRequest{
+		Endpoint: endpointOf(r),
+		Method:   r.Method,
+		URL:      r.URL.String(),
+		Header:   r.Header.Clone(),
+	}
+
+	var data []byte
+	if r.GetBody != nil {
+		if body, err := r.GetBody(); err == nil {
+			data, _ = io.ReadAll(body)
+			body.Close()
+		}
+	}
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		prepared.JSON = RedactJSON(data)
+	} else {
+		form, _ := url.ParseQuery(string(data))
+		prepared.Form = RedactParams(form)
+	}
+
+	return prepared
+}
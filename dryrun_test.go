@@ -0,0 +1,98 @@
+package sberbank_acquiring_go
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"testing"
+
+	"github.com/helios-ag/sberbank-acquiring-go/endpoints"
+	server "github.com/helios-ag/sberbank-acquiring-go/testing"
+	. "github.com/onsi/gomega"
+)
+
+func TestDryRun(t *testing.T) {
+	RegisterTestingT(t)
+
+	testServer := server.NewServer()
+	defer testServer.Teardown()
+
+	var requests atomic.Int32
+	testServer.Mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	})
+
+	client, err := NewClient(testConfig, WithEndpoint(testServer.URL), WithUserAgent("shop"))
+	Expect(err).ToNot(HaveOccurred())
+	register := Operation[Form, orderResponse]{Method: http.MethodPost, Path: endpoints.Register}
+
+	t.Run("REST request", func(t *testing.T) {
+		prepared, err := DryRun(context.Background(), func(ctx context.Context) error {
+			_, _, err := Call(ctx, client, register, Form{
+				Params:     map[string]string{"orderNumber": "42", "pan": "4111111111111111"},
+				JSONParams: map[string]string{"email": "test@example.com"},
+			})
+			return err
+		})
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(requests.Load()).To(BeZero())
+		Expect(prepared.Endpoint).To(Equal(endpoints.Register))
+		Expect(prepared.Method).To(Equal(http.MethodPost))
+		Expect(prepared.URL).To(Equal(testServer.URL + endpoints.Register))
+		Expect(prepared.Header.Get("User-Agent")).To(Equal("shop"))
+		Expect(prepared.JSON).To(BeNil())
+		Expect(prepared.Form).To(Equal(url.Values{
+			"userName":    {"test-api"},
+			"password":    {Redacted},
+			"orderNumber": {"42"},
+			"pan":         {Redacted},
+			"jsonParams":  {`{"email":"test@example.com"}`},
+		}))
+		Expect(prepared.Body()).To(ContainSubstring("password=%5BREDACTED%5D"))
+	})
+
+	t.Run("JSON request", func(t *testing.T) {
+		applePay := Operation[map[string]string, orderResponse]{Method: http.MethodPost, Path: endpoints.ApplePay}
+		prepared, err := DryRun(context.Background(), func(ctx context.Context) error {
+			_, _, err := Call(ctx, client, applePay, map[string]string{"orderNumber": "42", "paymentToken": "secret"})
+			return err
+		})
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(requests.Load()).To(BeZero())
+		Expect(prepared.Form).To(BeNil())
+		Expect(prepared.JSON).To(MatchJSON(`{"orderNumber":"42","paymentToken":"[REDACTED]"}`))
+		Expect(prepared.Body()).To(Equal(string(prepared.JSON)))
+	})
+
+	t.Run("Errors of the call are returned", func(t *testing.T) {
+		_, err := DryRun(context.Background(), func(ctx context.Context) error {
+			return errors.New("orderNumber is required")
+		})
+		Expect(err).To(MatchError("orderNumber is required"))
+
+		_, err = DryRun(context.Background(), func(ctx context.Context) error {
+			return nil
+		})
+		Expect(err).To(MatchError(ContainSubstring("no request is prepared")))
+	})
+
+	t.Run("Client mode", func(t *testing.T) {
+		dryRunClient, err := NewClient(testConfig, WithEndpoint(testServer.URL), WithDryRun())
+		Expect(err).ToNot(HaveOccurred())
+
+		resp, result, err := Call(context.Background(), dryRunClient, register, Form{Params: map[string]string{"orderNumber": "42"}})
+		Expect(resp).To(BeNil())
+		Expect(result).To(BeNil())
+		Expect(err).To(MatchError(ErrDryRun))
+		Expect(requests.Load()).To(BeZero())
+
+		var dryRunErr *DryRunError
+		Expect(errors.As(err, &dryRunErr)).To(BeTrue())
+		Expect(dryRunErr.Request.Form.Get("orderNumber")).To(Equal("42"))
+		Expect(err.Error()).To(Equal("dry run, POST " + testServer.URL + endpoints.Register + " is not sent"))
+	})
+}
@@ -10,9 +10,12 @@ const (
 	InstantRefund            string = "/payment/rest/instantRefund.do"
 	ProcessRawSumRefund      string = "/payment/rest/processRawSumRefund.do"
 	ProcessRawPositionRefund string = "/payment/rest/processRawPositionRefund.do"
+	AddParams                string = "/payment/rest/addParams.do"
 
-	GetOrderStatusExtended string = "/payment/rest/getOrderStatusExtended.do"
-	GetReceiptStatus       string = "/payment/rest/getReceiptStatus.do"
+	GetOrderStatus            string = "/payment/rest/getOrderStatus.do"
+	GetOrderStatusExtended    string = "/payment/rest/getOrderStatusExtended.do"
+	GetLastOrdersForMerchants string = "/payment/rest/getLastOrdersForMerchants.do"
+	GetReceiptStatus          string = "/payment/rest/getReceiptStatus.do"
 
 	UnBindCard             string = "/payment/rest/unBindCard.do"
 	BindCard               string = "/payment/rest/bindCard.do"
@@ -2,7 +2,6 @@ package enrollment
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/http"
 
@@ -15,6 +14,12 @@ type Client struct {
 	API acquiring.API
 }
 
+// NewClient creates a Client that sends requests through api,
+// usually a client created with acquiring.NewClient.
+func NewClient(api acquiring.API) Client {
+	return Client{API: api}
+}
+
 // VerifyEnrollment request
 // Checks if card enrolled in 3D Sec
 // see https://securepayments.sberbank.ru/wiki/doku.php/integration:api:rest:requests:verifyEnrollment
@@ -32,22 +37,16 @@ func (c Client) VerifyEnrollment(ctx context.Context, pan string) (*schema.Enrol
 		return nil, nil, err
 	}
 
-	body := make(map[string]string)
-	body["pan"] = pan
+	body := verifyEnrollmentRequest{PAN: pan}
 
-	var enrollmentResponse schema.EnrollmentResponse
-	req, err := c.API.NewRestRequest(ctx, http.MethodGet, path, body, nil)
+	op := acquiring.Operation[verifyEnrollmentRequest, schema.EnrollmentResponse]{Method: http.MethodGet, Path: path}
 
-	if err != nil {
-		return nil, nil, err
-	}
-	result, err := c.API.Do(req, &enrollmentResponse)
-	if err != nil {
-		return nil, result, err
-	}
-	_ = json.NewDecoder(result.Body).Decode(&enrollmentResponse)
+	return acquiring.Call(ctx, c.API, op, body)
+}
 
-	return &enrollmentResponse, result, err
+// verifyEnrollmentRequest is the body of verifyEnrollment.do request
+type verifyEnrollmentRequest struct {
+	PAN string `form:"pan"`
 }
 
 func validatePan(pan string) error {
@@ -24,8 +24,7 @@ func prepareClient(URL string) {
 		SessionTimeoutSecs: 1200,
 		SandboxMode:        true,
 	}
-	acquiring.SetConfig(cfg)
-	acquiring.WithEndpoint(URL)
+	acquiring.SetConfig(cfg, acquiring.WithEndpoint(URL))
 }
 
 func TestClient_VerifyEnrollment(t *testing.T) {
@@ -0,0 +1,345 @@
+package sberbank_acquiring_go
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/helios-ag/sberbank-acquiring-go/endpoints"
+)
+
+// ErrorClass tells how a caller should react to a gateway error.
+type ErrorClass int
+
+const (
+	// ErrorClassPermanent means that repeating the same request gives the same result.
+	ErrorClassPermanent ErrorClass = iota
+	// ErrorClassRetryable means that the request was not processed and can be repeated as is.
+	ErrorClassRetryable
+	// ErrorClassNeedsReconciliation means that the outcome of the request is unknown,
+	// order status should be checked before the request is repeated.
+	ErrorClassNeedsReconciliation
+)
+
+func (c ErrorClass) String() string {
+	switch c {
+	case ErrorClassPermanent:
+		return "permanent"
+	case ErrorClassRetryable:
+		return "retryable"
+	case ErrorClassNeedsReconciliation:
+		return "needs-reconciliation"
+	}
+
+	return "unknown"
+}
+
+// Meanings of gateway error codes
+const (
+	MeaningOrderAlreadyProcessed = "order_already_processed"
+	MeaningUnknownCurrency       = "unknown_currency"
+	MeaningMissingParameter      = "missing_parameter"
+	MeaningAccessDenied          = "access_denied"
+	MeaningOrderNotFound         = "order_not_found"
+	MeaningWrongOrderState       = "wrong_order_state"
+	MeaningSystemError           = "system_error"
+	MeaningNotPermitted          = "not_permitted"
+	MeaningInvalidFeatures       = "invalid_features"
+	MeaningBindingState          = "binding_state"
+	MeaningNotFound              = "not_found"
+	MeaningOrderDeclined         = "order_declined"
+)
+
+// ErrorCodeInfo describes an errorCode returned by a gateway method.
+type ErrorCodeInfo struct {
+	Code          int
+	Meaning       string
+	DescriptionRU string
+	DescriptionEN string
+	Class         ErrorClass
+	// Err is the sentinel error matched by errors.Is, if any
+	Err error
+	// OrderState means that the code describes the state of the order and the response is not an error,
+	// e.g. errorCode 2 of a declined order returned by getOrderStatus.do
+	OrderState bool
+}
+
+var (
+	codeAccessDenied = ErrorCodeInfo{
+		Code:          5,
+		Meaning:       MeaningAccessDenied,
+		DescriptionRU: "Доступ запрещён или неверное значение параметра",
+		DescriptionEN: "Access denied or invalid parameter value",
+		Class:         ErrorClassPermanent,
+		Err:           ErrAccessDenied,
+	}
+	codeOrderNotFound = ErrorCodeInfo{
+		Code:          6,
+		Meaning:       MeaningOrderNotFound,
+		DescriptionRU: "Заказ не найден",
+		DescriptionEN: "Order not found",
+		Class:         ErrorClassPermanent,
+		Err:           ErrOrderNotFound,
+	}
+	// codeSystemErrorUnsafe is the system error of the methods that move money:
+	// the operation could have been performed before the error occurred.
+	codeSystemErrorUnsafe = ErrorCodeInfo{
+		Code:          7,
+		Meaning:       MeaningSystemError,
+		DescriptionRU: "Системная ошибка",
+		DescriptionEN: "System error",
+		Class:         ErrorClassNeedsReconciliation,
+		Err:           ErrSystemError,
+	}
+	// codeSystemErrorSafe is the system error of the read-only methods.
+	codeSystemErrorSafe = ErrorCodeInfo{
+		Code:          7,
+		Meaning:       MeaningSystemError,
+		DescriptionRU: "Системная ошибка",
+		DescriptionEN: "System error",
+		Class:         ErrorClassRetryable,
+		Err:           ErrSystemError,
+	}
+	codeMissingParameter = ErrorCodeInfo{
+		Code:          4,
+		Meaning:       MeaningMissingParameter,
+		DescriptionRU: "Не указан обязательный параметр",
+		DescriptionEN: "Required parameter is missing",
+		Class:         ErrorClassPermanent,
+	}
+	codeWrongOrderStateUnsafe = ErrorCodeInfo{
+		Code:          7,
+		Meaning:       MeaningWrongOrderState,
+		DescriptionRU: "Платёж должен быть в корректном состоянии или системная ошибка",
+		DescriptionEN: "Payment must be in a correct state or system error",
+		Class:         ErrorClassNeedsReconciliation,
+		Err:           ErrWrongOrderState,
+	}
+)
+
+var registerErrorCodes = map[int]ErrorCodeInfo{
+	1: {
+		Code:          1,
+		Meaning:       MeaningOrderAlreadyProcessed,
+		DescriptionRU: "Заказ с таким номером уже обработан",
+		DescriptionEN: "Order with this number was already processed",
+		Class:         ErrorClassPermanent,
+		Err:           ErrOrderAlreadyPaid,
+	},
+	3: {
+		Code:          3,
+		Meaning:       MeaningUnknownCurrency,
+		DescriptionRU: "Неизвестная валюта",
+		DescriptionEN: "Unknown currency",
+		Class:         ErrorClassPermanent,
+	},
+	4: codeMissingParameter,
+	5: codeAccessDenied,
+	7: codeSystemErrorUnsafe,
+	13: {
+		Code:          13,
+		Meaning:       MeaningNotPermitted,
+		DescriptionRU: "Мерчант не имеет привилегии для выполнения операции",
+		DescriptionEN: "Merchant is not permitted to perform the operation",
+		Class:         ErrorClassPermanent,
+	},
+	14: {
+		Code:          14,
+		Meaning:       MeaningInvalidFeatures,
+		DescriptionRU: "Features указаны некорректно",
+		DescriptionEN: "Features are invalid",
+		Class:         ErrorClassPermanent,
+	},
+}
+
+var paymentErrorCodes = map[int]ErrorCodeInfo{
+	5: codeAccessDenied,
+	6: codeOrderNotFound,
+	7: codeWrongOrderStateUnsafe,
+}
+
+// operationErrorCodes are the codes of the methods that move money or change bindings
+// without an order of the merchant: instant refund, mobile payments, binding creation.
+var operationErrorCodes = map[int]ErrorCodeInfo{
+	4: codeMissingParameter,
+	5: codeAccessDenied,
+	7: codeSystemErrorUnsafe,
+}
+
+var statusErrorCodes = map[int]ErrorCodeInfo{
+	1: {
+		Code:          1,
+		Meaning:       MeaningMissingParameter,
+		DescriptionRU: "Ожидается orderId или orderNumber",
+		DescriptionEN: "orderId or orderNumber is expected",
+		Class:         ErrorClassPermanent,
+	},
+	5: codeAccessDenied,
+	6: codeOrderNotFound,
+	7: codeSystemErrorSafe,
+}
+
+var basicStatusErrorCodes = map[int]ErrorCodeInfo{
+	2: {
+		Code:          2,
+		Meaning:       MeaningOrderDeclined,
+		DescriptionRU: "Заказ отклонён по причине ошибки в реквизитах платежа",
+		DescriptionEN: "Order is declined because of invalid payment details",
+		Class:         ErrorClassPermanent,
+		OrderState:    true,
+	},
+	5: codeAccessDenied,
+	6: codeOrderNotFound,
+	7: codeSystemErrorSafe,
+}
+
+var addParamsErrorCodes = map[int]ErrorCodeInfo{
+	5: codeAccessDenied,
+	6: codeOrderNotFound,
+	7: codeSystemErrorSafe,
+}
+
+var lastOrdersErrorCodes = map[int]ErrorCodeInfo{
+	5: codeAccessDenied,
+	7: codeSystemErrorSafe,
+}
+
+var bindingErrorCodes = map[int]ErrorCodeInfo{
+	2: {
+		Code:          2,
+		Meaning:       MeaningBindingState,
+		DescriptionRU: "Связка уже активна или деактивирована",
+		DescriptionEN: "Binding is already active or inactive",
+		Class:         ErrorClassPermanent,
+	},
+	5: codeAccessDenied,
+	7: codeSystemErrorSafe,
+}
+
+var getBindingsErrorCodes = map[int]ErrorCodeInfo{
+	1: {
+		Code:          1,
+		Meaning:       MeaningMissingParameter,
+		DescriptionRU: "Ожидается clientId",
+		DescriptionEN: "clientId is expected",
+		Class:         ErrorClassPermanent,
+	},
+	2: {
+		Code:          2,
+		Meaning:       MeaningNotFound,
+		DescriptionRU: "Информация не найдена",
+		DescriptionEN: "No information found",
+		Class:         ErrorClassPermanent,
+	},
+	5: codeAccessDenied,
+	7: codeSystemErrorSafe,
+}
+
+var enrollmentErrorCodes = map[int]ErrorCodeInfo{
+	1: {
+		Code:          1,
+		Meaning:       MeaningMissingParameter,
+		DescriptionRU: "Не указан номер карты",
+		DescriptionEN: "Card number is missing",
+		Class:         ErrorClassPermanent,
+	},
+	5: codeAccessDenied,
+	7: codeSystemErrorSafe,
+}
+
+// readOnlyEndpoints are endpoints that don't change state of orders and bindings.
+var readOnlyEndpoints = map[string]bool{
+	endpoints.GetOrderStatus:            true,
+	endpoints.GetOrderStatusExtended:    true,
+	endpoints.GetLastOrdersForMerchants: true,
+	endpoints.GetReceiptStatus:          true,
+	endpoints.GetBindings:               true,
+	endpoints.GetBindingsByCardOrId:     true,
+	endpoints.VerifyEnrollment:          true,
+}
+
+// errorCatalog maps endpoints to meaning of their error codes.
+var errorCatalog = map[string]map[int]ErrorCodeInfo{
+	endpoints.Register:                  registerErrorCodes,
+	endpoints.RegisterPreAuth:           registerErrorCodes,
+	endpoints.Deposit:                   paymentErrorCodes,
+	endpoints.Reverse:                   paymentErrorCodes,
+	endpoints.Refund:                    paymentErrorCodes,
+	endpoints.Decline:                   paymentErrorCodes,
+	endpoints.ProcessRawSumRefund:       paymentErrorCodes,
+	endpoints.ProcessRawPositionRefund:  paymentErrorCodes,
+	endpoints.InstantRefund:             operationErrorCodes,
+	endpoints.ApplePay:                  operationErrorCodes,
+	endpoints.GooglePay:                 operationErrorCodes,
+	endpoints.SamsungPay:                operationErrorCodes,
+	endpoints.SamsungWebPay:             operationErrorCodes,
+	endpoints.MirPay:                    operationErrorCodes,
+	endpoints.MirPayDirect:              operationErrorCodes,
+	endpoints.CreateBindingNoPayment:    operationErrorCodes,
+	endpoints.ExternalReceipt:           operationErrorCodes,
+	endpoints.UpdateSSLCardList:         operationErrorCodes,
+	endpoints.AddParams:                 addParamsErrorCodes,
+	endpoints.GetOrderStatus:            basicStatusErrorCodes,
+	endpoints.GetOrderStatusExtended:    statusErrorCodes,
+	endpoints.GetLastOrdersForMerchants: lastOrdersErrorCodes,
+	endpoints.GetReceiptStatus:          statusErrorCodes,
+	endpoints.BindCard:                  bindingErrorCodes,
+	endpoints.UnBindCard:                bindingErrorCodes,
+	endpoints.ExtendBinding:             bindingErrorCodes,
+	endpoints.GetBindings:               getBindingsErrorCodes,
+	endpoints.GetBindingsByCardOrId:     getBindingsErrorCodes,
+	endpoints.VerifyEnrollment:          enrollmentErrorCodes,
+}
+
+// LookupErrorCode returns description of the error code returned by the endpoint,
+// e.g. LookupErrorCode(endpoints.Deposit, 6). Codes missing in the catalog are not described,
+// errors with them are classified by HTTP status code.
+func LookupErrorCode(endpoint string, code int) (ErrorCodeInfo, bool) {
+	info, ok := errorCatalog[endpoint][code]
+	return info, ok
+}
+
+// ErrorCodes returns known error codes of the endpoint ordered by code.
+func ErrorCodes(endpoint string) []ErrorCodeInfo {
+	codes := errorCatalog[endpoint]
+
+	result := make([]ErrorCodeInfo, 0, len(codes))
+	for _, info := range codes {
+		result = append(result, info)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Code < result[j].Code
+	})
+
+	return result
+}
+
+// Info returns catalog description of the error code.
+func (e *APIError) Info() (ErrorCodeInfo, bool) {
+	if e.ErrorCode == 0 {
+		return ErrorCodeInfo{}, false
+	}
+
+	return LookupErrorCode(e.Endpoint, e.ErrorCode)
+}
+
+// Class tells whether the failed request can be retried.
+// Errors without errorCode are classified by HTTP status code. The outcome of an uncatalogued
+// errorCode of a method that changes orders or bindings is unknown, it needs reconciliation.
+func (e *APIError) Class() ErrorClass {
+	if info, ok := e.Info(); ok {
+		return info.Class
+	}
+
+	switch {
+	case e.StatusCode == http.StatusTooManyRequests || e.StatusCode == http.StatusServiceUnavailable:
+		return ErrorClassRetryable
+	case e.ErrorCode != 0 && !readOnlyEndpoints[e.Endpoint]:
+		return ErrorClassNeedsReconciliation
+	case e.StatusCode >= http.StatusInternalServerError && readOnlyEndpoints[e.Endpoint]:
+		return ErrorClassRetryable
+	case e.StatusCode >= http.StatusInternalServerError:
+		return ErrorClassNeedsReconciliation
+	}
+
+	return ErrorClassPermanent
+}
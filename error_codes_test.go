@@ -0,0 +1,123 @@
+package sberbank_acquiring_go
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/helios-ag/sberbank-acquiring-go/endpoints"
+	. "github.com/onsi/gomega"
+)
+
+func TestLookupErrorCode(t *testing.T) {
+	RegisterTestingT(t)
+	t.Run("Same code means different things", func(t *testing.T) {
+		info, ok := LookupErrorCode(endpoints.Register, 1)
+		Expect(ok).To(BeTrue())
+		Expect(info.Meaning).To(Equal(MeaningOrderAlreadyProcessed))
+		Expect(info.Err).To(Equal(ErrOrderAlreadyPaid))
+
+		info, ok = LookupErrorCode(endpoints.GetOrderStatusExtended, 1)
+		Expect(ok).To(BeTrue())
+		Expect(info.Meaning).To(Equal(MeaningMissingParameter))
+		Expect(info.Err).To(BeNil())
+	})
+
+	t.Run("System error of money moving methods needs reconciliation", func(t *testing.T) {
+		for _, endpoint := range []string{endpoints.Register, endpoints.Deposit, endpoints.Refund, endpoints.Reverse} {
+			info, ok := LookupErrorCode(endpoint, 7)
+			Expect(ok).To(BeTrue())
+			Expect(info.Class).To(Equal(ErrorClassNeedsReconciliation), endpoint)
+		}
+
+		info, ok := LookupErrorCode(endpoints.GetOrderStatusExtended, 7)
+		Expect(ok).To(BeTrue())
+		Expect(info.Class).To(Equal(ErrorClassRetryable))
+	})
+
+	t.Run("Descriptions are present", func(t *testing.T) {
+		for endpoint := range errorCatalog {
+			for _, info := range ErrorCodes(endpoint) {
+				Expect(info.DescriptionRU).ToNot(BeEmpty(), endpoint)
+				Expect(info.DescriptionEN).ToNot(BeEmpty(), endpoint)
+				Expect(info.Meaning).ToNot(BeEmpty(), endpoint)
+			}
+		}
+	})
+
+	t.Run("Unknown codes are not described", func(t *testing.T) {
+		_, ok := LookupErrorCode(endpoints.InstantRefund, 6)
+		Expect(ok).To(BeFalse())
+
+		_, ok = LookupErrorCode(endpoints.Deposit, 42)
+		Expect(ok).To(BeFalse())
+
+		Expect(ErrorCodes("/payment/rest/unknown.do")).To(BeEmpty())
+	})
+
+	t.Run("Every endpoint is catalogued", func(t *testing.T) {
+		all := []string{
+			endpoints.Register, endpoints.RegisterPreAuth, endpoints.Deposit, endpoints.Reverse, endpoints.Refund,
+			endpoints.InstantRefund, endpoints.ProcessRawSumRefund, endpoints.ProcessRawPositionRefund, endpoints.AddParams,
+			endpoints.GetOrderStatus, endpoints.GetOrderStatusExtended, endpoints.GetLastOrdersForMerchants, endpoints.GetReceiptStatus,
+			endpoints.UnBindCard, endpoints.BindCard, endpoints.GetBindings, endpoints.GetBindingsByCardOrId,
+			endpoints.ExtendBinding, endpoints.CreateBindingNoPayment,
+			endpoints.ApplePay, endpoints.SamsungPay, endpoints.SamsungWebPay, endpoints.GooglePay, endpoints.MirPay, endpoints.MirPayDirect,
+			endpoints.Decline, endpoints.ExternalReceipt, endpoints.VerifyEnrollment, endpoints.UpdateSSLCardList,
+		}
+		for _, endpoint := range all {
+			info, ok := LookupErrorCode(endpoint, 5)
+			Expect(ok).To(BeTrue(), endpoint)
+			Expect(info.Err).To(Equal(ErrAccessDenied), endpoint)
+
+			info, ok = LookupErrorCode(endpoint, 7)
+			Expect(ok).To(BeTrue(), endpoint)
+			Expect(info.Err).ToNot(BeNil(), endpoint)
+		}
+	})
+
+	t.Run("Wrong order state is not a system error", func(t *testing.T) {
+		for _, endpoint := range []string{endpoints.Deposit, endpoints.Reverse, endpoints.Refund, endpoints.Decline} {
+			info, ok := LookupErrorCode(endpoint, 7)
+			Expect(ok).To(BeTrue())
+			Expect(info.Meaning).To(Equal(MeaningWrongOrderState), endpoint)
+			Expect(info.Err).To(Equal(ErrWrongOrderState), endpoint)
+		}
+	})
+
+	t.Run("Declined order of getOrderStatus.do is not an error", func(t *testing.T) {
+		info, ok := LookupErrorCode(endpoints.GetOrderStatus, 2)
+		Expect(ok).To(BeTrue())
+		Expect(info.Meaning).To(Equal(MeaningOrderDeclined))
+		Expect(info.OrderState).To(BeTrue())
+
+		info, ok = LookupErrorCode(endpoints.GetOrderStatusExtended, 2)
+		Expect(ok && info.OrderState).To(BeFalse())
+	})
+
+	t.Run("Codes are ordered", func(t *testing.T) {
+		codes := ErrorCodes(endpoints.Register)
+		Expect(codes).ToNot(BeEmpty())
+		for i := 1; i < len(codes); i++ {
+			Expect(codes[i-1].Code).To(BeNumerically("<", codes[i].Code))
+		}
+	})
+}
+
+func TestAPIErrorClass(t *testing.T) {
+	RegisterTestingT(t)
+
+	Expect((&APIError{Endpoint: endpoints.Deposit, StatusCode: 200, ErrorCode: 6}).Class()).To(Equal(ErrorClassPermanent))
+	Expect((&APIError{Endpoint: endpoints.Deposit, StatusCode: 200, ErrorCode: 7}).Class()).To(Equal(ErrorClassNeedsReconciliation))
+	Expect((&APIError{Endpoint: endpoints.GetBindings, StatusCode: 200, ErrorCode: 7}).Class()).To(Equal(ErrorClassRetryable))
+	Expect((&APIError{Endpoint: endpoints.Deposit, StatusCode: http.StatusServiceUnavailable}).Class()).To(Equal(ErrorClassRetryable))
+	Expect((&APIError{Endpoint: endpoints.Deposit, StatusCode: http.StatusBadGateway}).Class()).To(Equal(ErrorClassNeedsReconciliation))
+	Expect((&APIError{Endpoint: endpoints.GetOrderStatusExtended, StatusCode: http.StatusBadGateway}).Class()).To(Equal(ErrorClassRetryable))
+	Expect((&APIError{Endpoint: endpoints.Deposit, StatusCode: http.StatusBadRequest}).Class()).To(Equal(ErrorClassPermanent))
+	Expect((&APIError{Endpoint: endpoints.InstantRefund, StatusCode: 200, ErrorCode: 7}).Class()).To(Equal(ErrorClassNeedsReconciliation))
+	Expect((&APIError{Endpoint: endpoints.ApplePay, StatusCode: 200, ErrorCode: 7}).Class()).To(Equal(ErrorClassNeedsReconciliation))
+	Expect((&APIError{Endpoint: endpoints.InstantRefund, StatusCode: 200, ErrorCode: 42}).Class()).To(Equal(ErrorClassNeedsReconciliation))
+	Expect((&APIError{Endpoint: endpoints.Deposit, StatusCode: 200, ErrorCode: 42}).Class()).To(Equal(ErrorClassNeedsReconciliation))
+	Expect((&APIError{Endpoint: endpoints.GetOrderStatusExtended, StatusCode: 200, ErrorCode: 42}).Class()).To(Equal(ErrorClassPermanent))
+	Expect((&APIError{Endpoint: endpoints.ApplePay, StatusCode: 200, ErrorMessage: "payment is not successful"}).Class()).To(Equal(ErrorClassPermanent))
+	Expect(ErrorClassNeedsReconciliation.String()).To(Equal("needs-reconciliation"))
+}
@@ -0,0 +1,158 @@
+package sberbank_acquiring_go
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Errors reported by the gateway. An *APIError matches them with errors.Is.
+var (
+	ErrOrderAlreadyPaid = errors.New("order with this number is already processed")
+	ErrOrderNotFound    = errors.New("order not found")
+	ErrAccessDenied     = errors.New("access denied")
+	ErrSystemError      = errors.New("gateway system error")
+	ErrWrongOrderState  = errors.New("order is in a wrong state for the operation")
+)
+
+// APIError is returned by Client.Do when the gateway responds with a 4xx/5xx status code
+// or reports a non-zero errorCode (or "success": false for mobile payments) in the body.
+type APIError struct {
+	// Endpoint is the API path of the request, e.g. endpoints.Register
+	Endpoint string
+	// StatusCode is the HTTP status code of the response
+	StatusCode int
+	// ErrorCode is the errorCode from the response body
+	ErrorCode int
+	// ErrorMessage is the errorMessage from the response body
+	ErrorMessage string
+	// Body is the raw response body
+	Body []byte
+}
+
+func (e *APIError) Error() string {
+	if e.ErrorCode == 0 && e.ErrorMessage == "" {
+		return fmt.Sprintf("sberbank server responded with status code %d", e.StatusCode)
+	}
+
+	return fmt.Sprintf("sberbank error %d on %s: %s", e.ErrorCode, e.Endpoint, e.ErrorMessage)
+}
+
+// Is reports whether the error corresponds to one of the sentinel errors of the package.
+func (e *APIError) Is(target error) bool {
+	return target != nil && e.sentinel() == target
+}
+
+func (e *APIError) sentinel() error {
+	if info, ok := e.Info(); ok && info.Err != nil {
+		return info.Err
+	}
+
+	switch {
+	case e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden:
+		return ErrAccessDenied
+	case e.StatusCode >= http.StatusInternalServerError:
+		return ErrSystemError
+	}
+
+	return nil
+}
+
+// errorCode is the errorCode of the gateway, which is sent either as a string or as a number.
+type errorCode int
+
+func (c *errorCode) UnmarshalJSON(data []byte) error {
+	value := strings.Trim(string(data), `"`)
+	if value == "" || value == "null" {
+		*c = 0
+		return nil
+	}
+
+	code, err := strconv.Atoi(value)
+	if err != nil {
+		return err
+	}
+	*c = errorCode(code)
+
+	return nil
+}
+
+type errorBody struct {
+	ErrorCode    errorCode       `json:"errorCode"`
+	ErrorMessage string          `json:"errorMessage"`
+	Success      *bool           `json:"success"`
+	Error        json.RawMessage `json:"error"`
+}
+
+type mobileError struct {
+	Code        errorCode `json:"code"`
+	Message     string    `json:"message"`
+	Description string    `json:"description"`
+}
+
+func errorFromResponse(resp *http.Response, body []byte) error {
+	var respBody errorBody
+	if err := json.Unmarshal(body, &respBody); err != nil {
+		return nil
+	}
+
+	apiErr := &APIError{
+		StatusCode:   resp.StatusCode,
+		ErrorCode:    int(respBody.ErrorCode),
+		ErrorMessage: respBody.ErrorMessage,
+		Body:         body,
+	}
+	if resp.Request != nil {
+		apiErr.Endpoint = endpointOf(resp.Request)
+	}
+
+	if respBody.Success != nil && !*respBody.Success {
+		var mobile mobileError
+		if json.Unmarshal(respBody.Error, &mobile) == nil {
+			apiErr.ErrorCode = int(mobile.Code)
+			apiErr.ErrorMessage = mobile.Message
+			if apiErr.ErrorMessage == "" {
+				apiErr.ErrorMessage = mobile.Description
+			}
+		}
+		if apiErr.ErrorMessage == "" {
+			apiErr.ErrorMessage = "payment is not successful"
+		}
+
+		return apiErr
+	}
+
+	if apiErr.ErrorCode == 0 && apiErr.ErrorMessage == "" {
+		return nil
+	}
+	if apiErr.ErrorCode == 0 && resp.StatusCode < http.StatusBadRequest {
+		// errorMessage without errorCode is a plain success message, e.g. "Успешно"
+		return nil
+	}
+	if info, ok := apiErr.Info(); ok && info.OrderState && resp.StatusCode < http.StatusBadRequest {
+		// the code reports the state of the order, which is decoded from the response
+		return nil
+	}
+
+	return apiErr
+}
+
+type endpointKey struct{}
+
+// withEndpoint stores API path of the request in ctx.
+func withEndpoint(ctx context.Context, urlPath string) context.Context {
+	return context.WithValue(ctx, endpointKey{}, urlPath)
+}
+
+// endpointOf returns API path of the request, e.g. endpoints.Register.
+func endpointOf(r *http.Request) string {
+	if endpoint, ok := r.Context().Value(endpointKey{}).(string); ok {
+		return endpoint
+	}
+
+	return r.URL.Path
+}
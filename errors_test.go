@@ -0,0 +1,101 @@
+package sberbank_acquiring_go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/helios-ag/sberbank-acquiring-go/endpoints"
+	server "github.com/helios-ag/sberbank-acquiring-go/testing"
+	. "github.com/onsi/gomega"
+)
+
+func TestAPIError(t *testing.T) {
+	RegisterTestingT(t)
+
+	do := func(path string, status int, body string) error {
+		testServer := server.NewServer()
+		defer testServer.Teardown()
+
+		testServer.Mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			fmt.Fprint(w, body)
+		})
+
+		client, err := NewClient(ClientConfig{UserName: "test-api", Password: "test"}, WithEndpoint(testServer.URL))
+		Expect(err).ToNot(HaveOccurred())
+
+		var req *http.Request
+		if path == endpoints.ApplePay {
+			req, err = client.NewRequest(context.Background(), http.MethodPost, path, nil)
+		} else {
+			req, err = client.NewRestRequest(context.Background(), http.MethodPost, path, nil, nil)
+		}
+		Expect(err).ToNot(HaveOccurred())
+		_, err = client.Do(req, nil)
+
+		return err
+	}
+
+	t.Run("errorCode in HTTP 200 response", func(t *testing.T) {
+		err := do(endpoints.Register, http.StatusOK, `{"errorCode":"1","errorMessage":"Заказ с таким номером уже обработан"}`)
+
+		var apiErr *APIError
+		Expect(errors.As(err, &apiErr)).To(BeTrue())
+		Expect(apiErr.Endpoint).To(Equal(endpoints.Register))
+		Expect(apiErr.StatusCode).To(Equal(http.StatusOK))
+		Expect(apiErr.ErrorCode).To(Equal(1))
+		Expect(apiErr.ErrorMessage).To(Equal("Заказ с таким номером уже обработан"))
+		Expect(string(apiErr.Body)).To(ContainSubstring(`"errorCode":"1"`))
+		Expect(err).To(MatchError(ErrOrderAlreadyPaid))
+	})
+
+	t.Run("errorCode as number", func(t *testing.T) {
+		err := do(endpoints.Deposit, http.StatusOK, `{"errorCode":6,"errorMessage":"Незарегистрированный OrderId"}`)
+		Expect(err).To(MatchError(ErrOrderNotFound))
+	})
+
+	t.Run("Sentinel errors", func(t *testing.T) {
+		Expect(do(endpoints.GetOrderStatusExtended, http.StatusOK, `{"errorCode":"5","errorMessage":"Доступ запрещён"}`)).To(MatchError(ErrAccessDenied))
+		Expect(do(endpoints.Register, http.StatusOK, `{"errorCode":"7","errorMessage":"Системная ошибка"}`)).To(MatchError(ErrSystemError))
+		Expect(do(endpoints.Refund, http.StatusOK, `{"errorCode":"7","errorMessage":"Платёж должен быть в корректном состоянии"}`)).To(MatchError(ErrWrongOrderState))
+		Expect(do(endpoints.Refund, http.StatusOK, `{"errorCode":"7","errorMessage":"Платёж должен быть в корректном состоянии"}`)).ToNot(MatchError(ErrSystemError))
+		Expect(do(endpoints.Refund, http.StatusInternalServerError, `Internal Server Error`)).To(MatchError(ErrSystemError))
+		Expect(do(endpoints.Refund, http.StatusForbidden, `Forbidden`)).To(MatchError(ErrAccessDenied))
+	})
+
+	t.Run("Code 1 is not already paid outside of registration", func(t *testing.T) {
+		err := do(endpoints.GetOrderStatusExtended, http.StatusOK, `{"errorCode":"1","errorMessage":"Ожидается orderId или orderNumber"}`)
+		Expect(err).To(HaveOccurred())
+		Expect(errors.Is(err, ErrOrderAlreadyPaid)).To(BeFalse())
+	})
+
+	t.Run("Successful responses", func(t *testing.T) {
+		Expect(do(endpoints.Deposit, http.StatusOK, `{"errorCode":"0","errorMessage":"Успешно"}`)).To(Succeed())
+		Expect(do(endpoints.Register, http.StatusOK, `{"orderId":"123","formUrl":"https://form"}`)).To(Succeed())
+		Expect(do(endpoints.ApplePay, http.StatusOK, `{"success":true,"data":{"orderId":"123"}}`)).To(Succeed())
+	})
+
+	t.Run("Mobile payment error", func(t *testing.T) {
+		err := do(endpoints.ApplePay, http.StatusOK, `{"success":false,"error":{"code":"5","message":"Доступ запрещён","description":"access denied"}}`)
+
+		var apiErr *APIError
+		Expect(errors.As(err, &apiErr)).To(BeTrue())
+		Expect(apiErr.Endpoint).To(Equal(endpoints.ApplePay))
+		Expect(apiErr.ErrorCode).To(Equal(5))
+		Expect(apiErr.ErrorMessage).To(Equal("Доступ запрещён"))
+		Expect(apiErr.Class()).To(Equal(ErrorClassPermanent))
+		Expect(err).To(MatchError(ErrAccessDenied))
+	})
+
+	t.Run("Error message", func(t *testing.T) {
+		err := &APIError{Endpoint: endpoints.Deposit, StatusCode: 200, ErrorCode: 6, ErrorMessage: "Заказ не найден"}
+		Expect(err.Error()).To(Equal("sberbank error 6 on /payment/rest/deposit.do: Заказ не найден"))
+
+		err = &APIError{Endpoint: endpoints.Deposit, StatusCode: 502}
+		Expect(err.Error()).To(Equal("sberbank server responded with status code 502"))
+	})
+}
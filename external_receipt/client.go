@@ -15,11 +15,18 @@ type Client struct {
 	API acquiring.API
 }
 
+// NewClient creates a Client that sends requests through api,
+// usually a client created with acquiring.NewClient.
+func NewClient(api acquiring.API) Client {
+	return Client{API: api}
+}
+
 // ExternalReceiptRequest is used for building GetExternalReceipt request
 //
 // Language - язык в кодировке ISO 639-1. Если не указан — будет использован язык по умолчанию.
 // UserName - логин служебной учётной записи продавца.
 // Password - пароль служебной учётной записи продавца.
+// Token - токен продавца, передаётся вместо UserName и Password.
 // MdOrder - уникальный номер заказа в платёжном шлюзе.
 // Receipt - блок с параметрами чека.
 // CashboxID - идентификатор кассы.
@@ -29,11 +36,24 @@ type ExternalReceiptRequest struct {
 	Language   *string     `json:"language,omitempty"`   // Язык в кодировке ISO 639-1
 	UserName   string      `json:"userName"`             // Логин служебной учётной записи продавца
 	Password   string      `json:"password"`             // Пароль служебной учётной записи продавца
+	Token      string      `json:"token,omitempty"`      // Токен продавца
 	MdOrder    string      `json:"mdOrder"`              // Уникальный номер заказа в платёжном шлюзе
 	Receipt    *Receipt    `json:"receipt"`              // Блок с параметрами чека (структура Receipt уже определена)
 	JSONParams *JSONParams `json:"jsonParams,omitempty"` // Дополнительные параметры запроса
 }
 
+// externalReceiptRequest is the JSON body of externalReceipt.do request,
+// receipt and jsonParams are passed as JSON encoded strings
+type externalReceiptRequest struct {
+	UserName   string `json:"userName,omitempty"`
+	Password   string `json:"password,omitempty"`
+	Token      string `json:"token,omitempty"`
+	MdOrder    string `json:"mdOrder"`
+	Receipt    string `json:"receipt"`
+	Language   string `json:"language,omitempty"`
+	JSONParams string `json:"jsonParams,omitempty"`
+}
+
 type JSONParams struct {
 	CashboxID *int64 `json:"cashboxId,omitempty"` // Идентификатор кассы
 	BasketID  *int64 `json:"basketId,omitempty"`  // Идентификатор корзины покупки или возврата
@@ -64,39 +84,34 @@ func (c Client) GetExternalReceipt(ctx context.Context, externalReceipt External
 		return nil, nil, err
 	}
 
-	var receipt, _ = json.Marshal(externalReceipt.Receipt)
-	var jsonParams, _ = json.Marshal(externalReceipt.JSONParams)
-	body := map[string]string{
-		"userName": externalReceipt.UserName,
-		"password": externalReceipt.Password,
-		"mdOrder":  externalReceipt.MdOrder,
-		"receipt":  string(receipt),
+	receipt, _ := json.Marshal(externalReceipt.Receipt)
+	body := externalReceiptRequest{
+		UserName: externalReceipt.UserName,
+		Password: externalReceipt.Password,
+		Token:    externalReceipt.Token,
+		MdOrder:  externalReceipt.MdOrder,
+		Receipt:  string(receipt),
 	}
 	if externalReceipt.Language != nil {
-		body["language"] = *externalReceipt.Language
+		body.Language = *externalReceipt.Language
 	}
-
 	if externalReceipt.JSONParams != nil {
-		body["jsonParams"] = string(jsonParams)
+		jsonParams, _ := json.Marshal(externalReceipt.JSONParams)
+		body.JSONParams = string(jsonParams)
 	}
-	var response schema.ExternalReceipt
 
-	req, err := c.API.NewRequest(ctx, http.MethodPost, path, body)
+	op := acquiring.Operation[externalReceiptRequest, schema.ExternalReceipt]{Method: http.MethodPost, Path: path}
 
-	if err != nil {
-		return nil, nil, err
-	}
-	result, err := c.API.Do(req, &response)
-	if err != nil {
-		return nil, result, err
-	}
-
-	return &response, result, err
+	return acquiring.Call(ctx, c.API, op, body)
 }
 
 func validateExternalReceiptRequest(externalReceiptRequest ExternalReceiptRequest) error {
-	if externalReceiptRequest.UserName == "" || externalReceiptRequest.Password == "" || externalReceiptRequest.MdOrder == "" || externalReceiptRequest.Receipt == nil {
-		return fmt.Errorf("userName and Password and mdOrder and Receipt are required")
+	if externalReceiptRequest.MdOrder == "" || externalReceiptRequest.Receipt == nil {
+		return fmt.Errorf("mdOrder and Receipt are required")
+	}
+
+	if externalReceiptRequest.Token != "" && (externalReceiptRequest.UserName != "" || externalReceiptRequest.Password != "") {
+		return acquiring.ErrMixedCredentials
 	}
 
 	return nil
@@ -25,7 +25,7 @@ func TestExternalReceipt_Additional(t *testing.T) {
 
         _, _, err := GetExternalReceipt(context.Background(), req)
         Expect(err).To(HaveOccurred())
-        Expect(err.Error()).To(ContainSubstring("userName and Password and mdOrder and Receipt are required"))
+        Expect(err.Error()).To(ContainSubstring("mdOrder and Receipt are required"))
     })
 
     t.Run("Test successful request with order ID", func(t *testing.T) {
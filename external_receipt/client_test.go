@@ -24,8 +24,7 @@ func prepareClient(URL string) {
 		SessionTimeoutSecs: 1200,
 		SandboxMode:        true,
 	}
-	acquiring.SetConfig(cfg)
-	acquiring.WithEndpoint(URL)
+	acquiring.SetConfig(cfg, acquiring.WithEndpoint(URL))
 }
 
 var NewRestRequestStub = func(
@@ -48,7 +47,7 @@ func TestClient_GetExternalReceipt(t *testing.T) {
 		_, _, err := GetExternalReceipt(context.Background(), receipt)
 
 		Expect(err).To(HaveOccurred())
-		Expect(err.Error()).To(ContainSubstring("userName and Password and mdOrder and Receipt are required"))
+		Expect(err.Error()).To(ContainSubstring("mdOrder and Receipt are required"))
 
 	})
 
@@ -120,4 +119,61 @@ func TestClient_GetExternalReceipt(t *testing.T) {
 		// We dont care what underlying error happened
 		Expect(err).ToNot(HaveOccurred())
 	})
+
+	t.Run("GetExternalReceipt with token", func(t *testing.T) {
+		testServer := server.NewServer()
+		defer testServer.Teardown()
+		prepareClient(testServer.URL)
+
+		testServer.Mux.HandleFunc(endpoints.ExternalReceipt, func(w http.ResponseWriter, r *http.Request) {
+			var body map[string]string
+			Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+			Expect(body).To(HaveKeyWithValue("token", "merchant-token"))
+			Expect(body).ToNot(HaveKey("userName"))
+			Expect(body).ToNot(HaveKey("password"))
+			json.NewEncoder(w).Encode(schema.ExternalReceipt{})
+		})
+
+		receipt := ExternalReceiptRequest{
+			Token:   "merchant-token",
+			MdOrder: "test",
+			Receipt: &Receipt{
+				PaymentType: 1,
+			},
+		}
+
+		_, _, err := GetExternalReceipt(context.Background(), receipt)
+		Expect(err).ToNot(HaveOccurred())
+
+		receipt.UserName = "test"
+		_, _, err = GetExternalReceipt(context.Background(), receipt)
+		Expect(err).To(MatchError(acquiring.ErrMixedCredentials))
+	})
+
+	t.Run("GetExternalReceipt with client credentials", func(t *testing.T) {
+		testServer := server.NewServer()
+		defer testServer.Teardown()
+
+		client, err := acquiring.NewClient(acquiring.ClientConfig{}, acquiring.WithToken("merchant-token"), acquiring.WithEndpoint(testServer.URL))
+		Expect(err).ToNot(HaveOccurred())
+
+		testServer.Mux.HandleFunc(endpoints.ExternalReceipt, func(w http.ResponseWriter, r *http.Request) {
+			var body map[string]string
+			Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+			Expect(body).To(HaveKeyWithValue("token", "merchant-token"))
+			Expect(body).ToNot(HaveKey("userName"))
+			Expect(body).ToNot(HaveKey("password"))
+			json.NewEncoder(w).Encode(schema.ExternalReceipt{})
+		})
+
+		receipt := ExternalReceiptRequest{
+			MdOrder: "test",
+			Receipt: &Receipt{
+				PaymentType: 1,
+			},
+		}
+
+		_, _, err = NewClient(client).GetExternalReceipt(context.Background(), receipt)
+		Expect(err).ToNot(HaveOccurred())
+	})
 }
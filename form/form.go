@@ -0,0 +1,195 @@
+// Package form encodes request structs into form values of REST requests.
+//
+// Fields are named with the form tag, fields without the tag are skipped:
+//
+//	type depositRequest struct {
+//		OrderId    string            `form:"orderId"`
+//		Amount     int               `form:"amount,omitempty"`
+//		JSONParams map[string]string `form:"jsonParams,omitempty"`
+//	}
+//
+// Strings, numbers and bools are formatted with strconv, pointers are dereferenced,
+// time.Time is formatted with TimeLayout, encoding.TextMarshaler is used when implemented,
+// and structs, maps and slices are encoded as JSON. Fields with the omitempty option
+// are skipped when they hold a zero value, a nil pointer or an empty map or slice.
+// Untagged embedded structs are flattened.
+package form
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TimeLayout is the format of time.Time values expected by the gateway.
+const TimeLayout = "2006-01-02T15:04:05"
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+type field struct {
+	name      string
+	index     []int
+	omitEmpty bool
+}
+
+var fieldsCache sync.Map // map[reflect.Type][]field
+
+// Encode returns form values of v, which must be a struct or a pointer to a struct.
+func Encode(v interface{}) (map[string]string, error) {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil, fmt.Errorf("form: can't encode nil %s", value.Type())
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("form: can't encode %s, struct expected", value.Type())
+	}
+
+	values := make(map[string]string)
+	for _, f := range fields(value.Type()) {
+		fieldValue, ok := fieldByIndex(value, f.index)
+		if !ok {
+			continue
+		}
+		if f.omitEmpty && isEmpty(fieldValue) {
+			continue
+		}
+
+		encoded, ok, err := encodeValue(fieldValue)
+		if err != nil {
+			return nil, fmt.Errorf("form: field %s: %w", f.name, err)
+		}
+		if ok {
+			values[f.name] = encoded
+		}
+	}
+
+	return values, nil
+}
+
+// HasTags reports whether v is a struct (or a pointer to a struct) with form tags.
+func HasTags(v interface{}) bool {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return false
+	}
+
+	return len(fields(t)) > 0
+}
+
+func fields(t reflect.Type) []field {
+	if cached, ok := fieldsCache.Load(t); ok {
+		return cached.([]field)
+	}
+
+	var result []field
+	for i := 0; i < t.NumField(); i++ {
+		structField := t.Field(i)
+		tag, tagged := structField.Tag.Lookup("form")
+
+		if !tagged && structField.Anonymous {
+			embedded := structField.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				for _, f := range fields(embedded) {
+					f.index = append([]int{i}, f.index...)
+					result = append(result, f)
+				}
+			}
+			continue
+		}
+		if !tagged || tag == "-" || !structField.IsExported() {
+			continue
+		}
+
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = structField.Name
+		}
+		result = append(result, field{
+			name:      name,
+			index:     []int{i},
+			omitEmpty: options == "omitempty",
+		})
+	}
+
+	fieldsCache.Store(t, result)
+
+	return result
+}
+
+// fieldByIndex is reflect.Value.FieldByIndex that stops on nil embedded pointers.
+func fieldByIndex(value reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && value.Kind() == reflect.Pointer {
+			if value.IsNil() {
+				return reflect.Value{}, false
+			}
+			value = value.Elem()
+		}
+		value = value.Field(x)
+	}
+
+	return value, true
+}
+
+func isEmpty(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.String:
+		return value.Len() == 0
+	case reflect.Pointer, reflect.Interface:
+		return value.IsNil()
+	}
+
+	return value.IsZero()
+}
+
+func encodeValue(value reflect.Value) (string, bool, error) {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return "", false, nil
+		}
+		value = value.Elem()
+	}
+
+	if value.Type() == timeType {
+		return value.Interface().(time.Time).Format(TimeLayout), true, nil
+	}
+	if value.Type().Implements(textMarshalerType) {
+		text, err := value.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text), err == nil, err
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		return value.String(), true, nil
+	case reflect.Bool:
+		return strconv.FormatBool(value.Bool()), true, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(value.Int(), 10), true, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(value.Uint(), 10), true, nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(value.Float(), 'f', -1, value.Type().Bits()), true, nil
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		encoded, err := json.Marshal(value.Interface())
+		return string(encoded), err == nil, err
+	}
+
+	return "", false, fmt.Errorf("unsupported type %s", value.Type())
+}
@@ -0,0 +1,140 @@
+package form
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+type status int
+
+func (s status) MarshalText() ([]byte, error) {
+	return []byte([]string{"CREATED", "APPROVED"}[s]), nil
+}
+
+type Credentials struct {
+	UserName string `form:"userName,omitempty"`
+	Password string `form:"password,omitempty"`
+}
+
+type bundle struct {
+	Items []string `json:"items"`
+}
+
+type request struct {
+	Credentials
+	OrderNumber     string            `form:"orderNumber"`
+	Amount          int               `form:"amount"`
+	Description     string            `form:"description,omitempty"`
+	BindingID       string            `form:"bindingId,omitempty"`
+	Currency        *int              `form:"currency,omitempty"`
+	PreAuth         *bool             `form:"preAuth,omitempty"`
+	Rate            float64           `form:"rate,omitempty"`
+	Expiration      time.Time         `form:"expirationDate,omitempty"`
+	Bundle          bundle            `form:"orderBundle,omitempty"`
+	JSONParams      map[string]string `form:"jsonParams,omitempty"`
+	Status          status            `form:"status"`
+	Untagged        string
+	Ignored         string `form:"-"`
+	unexported      string `form:"unexported"`
+	DefaultName     uint8  `form:""`
+	EmptyNotOmitted string `form:"empty"`
+}
+
+func TestEncode(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Set and empty values", func(t *testing.T) {
+		currency := 643
+		preAuth := false
+		values, err := Encode(request{
+			Credentials: Credentials{UserName: "test-api"},
+			OrderNumber: "42",
+			Amount:      100,
+			Currency:    &currency,
+			PreAuth:     &preAuth,
+			Rate:        1.5,
+			Expiration:  time.Date(2030, 12, 31, 23, 59, 0, 0, time.UTC),
+			Bundle:      bundle{Items: []string{"a"}},
+			JSONParams:  map[string]string{"email": "test@example.com"},
+			Status:      1,
+			Untagged:    "untagged",
+			Ignored:     "ignored",
+			unexported:  "unexported",
+			DefaultName: 7,
+		})
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(values).To(Equal(map[string]string{
+			"userName":       "test-api",
+			"orderNumber":    "42",
+			"amount":         "100",
+			"currency":       "643",
+			"preAuth":        "false",
+			"rate":           "1.5",
+			"expirationDate": "2030-12-31T23:59:00",
+			"orderBundle":    `{"items":["a"]}`,
+			"jsonParams":     `{"email":"test@example.com"}`,
+			"status":         "APPROVED",
+			"DefaultName":    "7",
+			"empty":          "",
+		}))
+	})
+
+	t.Run("Omit empty", func(t *testing.T) {
+		values, err := Encode(&request{JSONParams: map[string]string{}})
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(values).To(Equal(map[string]string{
+			"orderNumber": "",
+			"amount":      "0",
+			"status":      "CREATED",
+			"DefaultName": "0",
+			"empty":       "",
+		}))
+	})
+
+	t.Run("Embedded pointer", func(t *testing.T) {
+		type withPointer struct {
+			*Credentials
+			OrderId string `form:"orderId"`
+		}
+
+		values, err := Encode(withPointer{OrderId: "42"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(values).To(Equal(map[string]string{"orderId": "42"}))
+
+		values, err = Encode(withPointer{Credentials: &Credentials{Password: "test"}, OrderId: "42"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(values).To(Equal(map[string]string{"orderId": "42", "password": "test"}))
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		_, err := Encode("string")
+		Expect(err).To(MatchError(ContainSubstring("struct expected")))
+
+		_, err = Encode((*request)(nil))
+		Expect(err).To(MatchError(ContainSubstring("can't encode nil")))
+
+		_, err = Encode(struct {
+			Callback func() `form:"callback"`
+		}{Callback: func() {}})
+		Expect(err).To(MatchError(ContainSubstring("field callback: unsupported type")))
+	})
+}
+
+func TestHasTags(t *testing.T) {
+	RegisterTestingT(t)
+
+	Expect(HasTags(request{})).To(BeTrue())
+	Expect(HasTags(&request{})).To(BeTrue())
+	Expect(HasTags(struct {
+		Credentials
+	}{})).To(BeTrue())
+	Expect(HasTags(struct {
+		Name string `json:"name"`
+	}{})).To(BeFalse())
+	Expect(HasTags(map[string]string{})).To(BeFalse())
+	Expect(HasTags(nil)).To(BeFalse())
+}
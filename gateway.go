@@ -0,0 +1,229 @@
+package sberbank_acquiring_go
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/helios-ag/sberbank-acquiring-go/endpoints"
+)
+
+// ErrFeatureNotSupported is returned when the gateway of the client doesn't support the requested method.
+var ErrFeatureNotSupported = errors.New("method is not supported by the gateway")
+
+// Feature is a group of gateway methods that not every RBS-based acquirer provides.
+// Registration, deposit, reverse, refund and order status are always supported.
+type Feature string
+
+const (
+	FeaturePreAuth         Feature = "preauth"
+	FeatureBindings        Feature = "bindings"
+	FeatureApplePay        Feature = "apple_pay"
+	FeatureGooglePay       Feature = "google_pay"
+	FeatureSamsungPay      Feature = "samsung_pay"
+	FeatureMirPay          Feature = "mir_pay"
+	FeatureDecline         Feature = "decline"
+	FeatureInstantRefund   Feature = "instant_refund"
+	FeatureRawRefunds      Feature = "raw_refunds"
+	FeatureReceiptStatus   Feature = "receipt_status"
+	FeatureExternalReceipt Feature = "external_receipt"
+	FeatureEnrollment      Feature = "enrollment"
+	FeatureSSLCardList     Feature = "ssl_card_list"
+	FeatureOrderParams     Feature = "order_params"
+	FeatureLastOrders      Feature = "last_orders"
+)
+
+// endpointFeatures maps endpoints to the features they belong to.
+var endpointFeatures = map[string]Feature{
+	endpoints.RegisterPreAuth:           FeaturePreAuth,
+	endpoints.BindCard:                  FeatureBindings,
+	endpoints.UnBindCard:                FeatureBindings,
+	endpoints.GetBindings:               FeatureBindings,
+	endpoints.GetBindingsByCardOrId:     FeatureBindings,
+	endpoints.ExtendBinding:             FeatureBindings,
+	endpoints.CreateBindingNoPayment:    FeatureBindings,
+	endpoints.ApplePay:                  FeatureApplePay,
+	endpoints.GooglePay:                 FeatureGooglePay,
+	endpoints.SamsungPay:                FeatureSamsungPay,
+	endpoints.SamsungWebPay:             FeatureSamsungPay,
+	endpoints.MirPay:                    FeatureMirPay,
+	endpoints.MirPayDirect:              FeatureMirPay,
+	endpoints.Decline:                   FeatureDecline,
+	endpoints.InstantRefund:             FeatureInstantRefund,
+	endpoints.ProcessRawSumRefund:       FeatureRawRefunds,
+	endpoints.ProcessRawPositionRefund:  FeatureRawRefunds,
+	endpoints.GetReceiptStatus:          FeatureReceiptStatus,
+	endpoints.ExternalReceipt:           FeatureExternalReceipt,
+	endpoints.VerifyEnrollment:          FeatureEnrollment,
+	endpoints.UpdateSSLCardList:         FeatureSSLCardList,
+	endpoints.AddParams:                 FeatureOrderParams,
+	endpoints.GetLastOrdersForMerchants: FeatureLastOrders,
+}
+
+// Gateway is the profile of an acquirer running the RBS payment gateway.
+type Gateway struct {
+	// Name is the key of the gateway in the registry
+	Name string
+	// URL is the production base URL, e.g. "https://securepayments.sberbank.ru"
+	URL string
+	// SandboxURL is the test base URL used with ClientConfig.SandboxMode
+	SandboxURL string
+	// Paths overrides API paths of the gateway, keyed by constants of the endpoints package
+	Paths map[string]string
+	// Features are the supported optional methods, nil means all of them
+	Features []Feature
+}
+
+// Supports reports whether the gateway provides the feature.
+func (g Gateway) Supports(feature Feature) bool {
+	return g.Features == nil || slices.Contains(g.Features, feature)
+}
+
+// Path returns the path of the endpoint on the gateway.
+func (g Gateway) Path(endpoint string) string {
+	if path, ok := g.Paths[endpoint]; ok {
+		return path
+	}
+
+	return endpoint
+}
+
+// BaseURL returns the production or sandbox base URL of the gateway.
+func (g Gateway) BaseURL(sandbox bool) string {
+	if sandbox {
+		return g.SandboxURL
+	}
+
+	return g.URL
+}
+
+// Built-in gateways
+const (
+	GatewaySberbank = "sberbank"
+	GatewayAlfaBank = "alfabank"
+)
+
+var (
+	gatewaysMu sync.RWMutex
+	gateways   = map[string]Gateway{
+		GatewaySberbank: {
+			Name:       GatewaySberbank,
+			URL:        APIURI,
+			SandboxURL: APISandboxURI,
+		},
+		GatewayAlfaBank: {
+			Name:       GatewayAlfaBank,
+			URL:        "https://pay.alfabank.ru",
+			SandboxURL: "https://alfa.rbsuat.com",
+			Features: []Feature{
+				FeaturePreAuth,
+				FeatureBindings,
+				FeatureApplePay,
+				FeatureGooglePay,
+				FeatureSamsungPay,
+				FeatureDecline,
+				FeatureReceiptStatus,
+				FeatureEnrollment,
+			},
+		},
+	}
+)
+
+// RegisterGateway adds the gateway to the registry or replaces the one with the same name.
+func RegisterGateway(gateway Gateway) error {
+	if gateway.Name == "" || gateway.URL == "" {
+		return fmt.Errorf("gateway name and URL are required")
+	}
+
+	gatewaysMu.Lock()
+	gateways[gateway.Name] = gateway
+	gatewaysMu.Unlock()
+
+	return nil
+}
+
+// LookupGateway returns the registered gateway.
+func LookupGateway(name string) (Gateway, bool) {
+	gatewaysMu.RLock()
+	gateway, ok := gateways[name]
+	gatewaysMu.RUnlock()
+
+	return gateway, ok
+}
+
+// GatewayNames returns names of registered gateways in alphabetical order.
+func GatewayNames() []string {
+	gatewaysMu.RLock()
+	names := make([]string, 0, len(gateways))
+	for name := range gateways {
+		names = append(names, name)
+	}
+	gatewaysMu.RUnlock()
+	sort.Strings(names)
+
+	return names
+}
+
+// WithGateway configures a Client to send requests to the gateway instead of Sberbank.
+// WithEndpoint still overrides the base URL.
+func WithGateway(gateway Gateway) ClientOption {
+	return func(c *Client) {
+		c.gateway = &gateway
+	}
+}
+
+// WithGatewayName is WithGateway with a registered gateway, unknown names are reported by NewClient.
+func WithGatewayName(name string) ClientOption {
+	return func(c *Client) {
+		gateway, ok := LookupGateway(name)
+		if !ok {
+			c.setErr(fmt.Errorf("unknown gateway %q", name))
+			return
+		}
+		c.gateway = &gateway
+	}
+}
+
+// url returns the URL of the endpoint on the gateway of the client.
+func (c *Client) url(urlPath string) (string, error) {
+	gateway := c.currentGateway()
+
+	if feature, ok := endpointFeatures[urlPath]; ok && !gateway.Supports(feature) {
+		return "", fmt.Errorf("%w: %s on %s", ErrFeatureNotSupported, urlPath, gateway.Name)
+	}
+
+	base, err := c.baseURL()
+	if err != nil {
+		return "", err
+	}
+
+	return base + gateway.Path(urlPath), nil
+}
+
+// currentGateway returns the gateway of the client, Sberbank by default.
+func (c *Client) currentGateway() Gateway {
+	if c.gateway != nil {
+		return *c.gateway
+	}
+	sberbank, _ := LookupGateway(GatewaySberbank)
+
+	return sberbank
+}
+
+// baseURL returns the base URL requests are sent to. A gateway without SandboxURL can be used
+// in sandbox mode only with WithEndpoint.
+func (c *Client) baseURL() (string, error) {
+	if c.Config.endpoint != "" {
+		return c.Config.endpoint, nil
+	}
+
+	gateway := c.currentGateway()
+	base := gateway.BaseURL(c.Config.SandboxMode)
+	if base == "" {
+		return "", fmt.Errorf("gateway %s has no sandbox URL, set WithEndpoint to use it in sandbox mode", gateway.Name)
+	}
+
+	return base, nil
+}
@@ -0,0 +1,159 @@
+package sberbank_acquiring_go
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/helios-ag/sberbank-acquiring-go/endpoints"
+	. "github.com/onsi/gomega"
+)
+
+func TestGateway(t *testing.T) {
+	RegisterTestingT(t)
+
+	gateway := Gateway{
+		Name:       "bank",
+		URL:        "https://pay.bank.ru",
+		SandboxURL: "https://test.bank.ru",
+		Paths:      map[string]string{endpoints.Register: "/ab/rest/register.do"},
+		Features:   []Feature{FeatureBindings, FeatureLastOrders},
+	}
+
+	Expect(gateway.BaseURL(false)).To(Equal("https://pay.bank.ru"))
+	Expect(gateway.BaseURL(true)).To(Equal("https://test.bank.ru"))
+	Expect(gateway.Path(endpoints.Register)).To(Equal("/ab/rest/register.do"))
+	Expect(gateway.Path(endpoints.Deposit)).To(Equal(endpoints.Deposit))
+	Expect(gateway.Supports(FeatureBindings)).To(BeTrue())
+	Expect(gateway.Supports(FeatureLastOrders)).To(BeTrue())
+	Expect(gateway.Supports(FeatureApplePay)).To(BeFalse())
+	Expect(gateway.Supports(FeatureOrderParams)).To(BeFalse())
+	Expect(Gateway{}.Supports(FeatureApplePay)).To(BeTrue())
+}
+
+func TestGatewayRegistry(t *testing.T) {
+	RegisterTestingT(t)
+
+	sberbank, ok := LookupGateway(GatewaySberbank)
+	Expect(ok).To(BeTrue())
+	Expect(sberbank.URL).To(Equal(APIURI))
+	Expect(sberbank.SandboxURL).To(Equal(APISandboxURI))
+
+	_, ok = LookupGateway("bank")
+	Expect(ok).To(BeFalse())
+
+	Expect(RegisterGateway(Gateway{Name: "bank", URL: "https://pay.bank.ru"})).To(Succeed())
+	defer func() {
+		gatewaysMu.Lock()
+		delete(gateways, "bank")
+		gatewaysMu.Unlock()
+	}()
+	Expect(GatewayNames()).To(Equal([]string{GatewayAlfaBank, "bank", GatewaySberbank}))
+
+	Expect(RegisterGateway(Gateway{Name: "no-url"})).To(MatchError(ContainSubstring("name and URL are required")))
+}
+
+func TestWithGateway(t *testing.T) {
+	RegisterTestingT(t)
+
+	gateway := Gateway{
+		Name:       "bank",
+		URL:        "https://pay.bank.ru",
+		SandboxURL: "https://test.bank.ru",
+		Paths:      map[string]string{endpoints.Register: "/ab/rest/register.do"},
+		Features:   []Feature{FeatureApplePay},
+	}
+
+	t.Run("Gateway URLs and paths", func(t *testing.T) {
+		client, err := NewClient(testConfig, WithGateway(gateway))
+		Expect(err).ToNot(HaveOccurred())
+
+		req, err := client.NewRestRequest(context.Background(), http.MethodPost, endpoints.Register, nil, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(req.URL.String()).To(Equal("https://pay.bank.ru/ab/rest/register.do"))
+		Expect(endpointOf(req)).To(Equal(endpoints.Register))
+
+		client, err = NewClient(testConfig, WithGateway(gateway), WithSandbox(true))
+		Expect(err).ToNot(HaveOccurred())
+
+		req, err = client.NewRequest(context.Background(), http.MethodPost, endpoints.ApplePay, map[string]string{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(req.URL.String()).To(Equal("https://test.bank.ru" + endpoints.ApplePay))
+
+		client, err = NewClient(testConfig, WithGateway(gateway), WithEndpoint("http://localhost:8080"))
+		Expect(err).ToNot(HaveOccurred())
+
+		req, err = client.NewRestRequest(context.Background(), http.MethodPost, endpoints.Register, nil, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(req.URL.String()).To(Equal("http://localhost:8080/ab/rest/register.do"))
+	})
+
+	t.Run("Unsupported features", func(t *testing.T) {
+		client, err := NewClient(testConfig, WithGateway(gateway))
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = client.NewRestRequest(context.Background(), http.MethodPost, endpoints.GetBindings, nil, nil)
+		Expect(err).To(MatchError(ErrFeatureNotSupported))
+		Expect(err).To(MatchError(ContainSubstring(endpoints.GetBindings + " on bank")))
+
+		_, err = client.NewRequest(context.Background(), http.MethodPost, endpoints.GooglePay, map[string]string{})
+		Expect(err).To(MatchError(ErrFeatureNotSupported))
+
+		_, err = client.NewRestRequest(context.Background(), http.MethodPost, endpoints.AddParams, nil, nil)
+		Expect(err).To(MatchError(ErrFeatureNotSupported))
+
+		_, err = client.NewRestRequest(context.Background(), http.MethodPost, endpoints.GetLastOrdersForMerchants, nil, nil)
+		Expect(err).To(MatchError(ErrFeatureNotSupported))
+
+		client, err = NewClient(testConfig, WithGateway(Gateway{
+			Name:     "bank",
+			URL:      "https://pay.bank.ru",
+			Features: []Feature{FeatureOrderParams, FeatureLastOrders},
+		}))
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = client.NewRestRequest(context.Background(), http.MethodPost, endpoints.AddParams, nil, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = client.NewRestRequest(context.Background(), http.MethodPost, endpoints.GetLastOrdersForMerchants, nil, nil)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	t.Run("Registered gateway", func(t *testing.T) {
+		client, err := NewClient(testConfig, WithGatewayName(GatewayAlfaBank), WithSandbox(true))
+		Expect(err).ToNot(HaveOccurred())
+
+		req, err := client.NewRestRequest(context.Background(), http.MethodPost, endpoints.Deposit, nil, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(req.URL.String()).To(Equal("https://alfa.rbsuat.com" + endpoints.Deposit))
+
+		_, err = NewClient(testConfig, WithGatewayName("unknown"))
+		Expect(err).To(MatchError(`unknown gateway "unknown"`))
+	})
+
+	t.Run("Gateway without sandbox", func(t *testing.T) {
+		production := Gateway{Name: "bank", URL: "https://pay.bank.ru"}
+
+		_, err := NewClient(testConfig, WithGateway(production), WithSandbox(true))
+		Expect(err).To(MatchError(ContainSubstring("gateway bank has no sandbox URL")))
+
+		client, err := NewClient(testConfig, WithGateway(production), WithSandbox(true), WithEndpoint("https://test.bank.ru"))
+		Expect(err).ToNot(HaveOccurred())
+		req, err := client.NewRestRequest(context.Background(), http.MethodPost, endpoints.Deposit, nil, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(req.URL.String()).To(Equal("https://test.bank.ru" + endpoints.Deposit))
+
+		client = newAPI(&ClientConfig{UserName: "test-api", Password: "test", SandboxMode: true}, WithGateway(production))
+		_, err = client.NewRestRequest(context.Background(), http.MethodPost, endpoints.Deposit, nil, nil)
+		Expect(err).To(MatchError(ContainSubstring("gateway bank has no sandbox URL")))
+	})
+
+	t.Run("Sberbank by default", func(t *testing.T) {
+		client, err := NewClient(testConfig)
+		Expect(err).ToNot(HaveOccurred())
+
+		req, err := client.NewRestRequest(context.Background(), http.MethodPost, endpoints.ProcessRawSumRefund, nil, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(req.URL.String()).To(Equal(APIURI + endpoints.ProcessRawSumRefund))
+	})
+}
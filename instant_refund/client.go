@@ -2,9 +2,7 @@ package instant_refund
 
 import (
 	"context"
-	"encoding/json"
 	"net/http"
-	"strconv"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	acquiring "github.com/helios-ag/sberbank-acquiring-go"
@@ -16,21 +14,28 @@ type Client struct {
 	API acquiring.API
 }
 
+// NewClient creates a Client that sends requests through api,
+// usually a client created with acquiring.NewClient.
+func NewClient(api acquiring.API) Client {
+	return Client{API: api}
+}
+
 // InstantRefundRequest Order is used to carry data related that passed to acquiring api requests.
 type InstantRefundRequest struct {
-	UserName       string
-	Password       string
-	Amount         int64
-	Language       *string
-	Currency       *int
-	OrderNumber    *string
-	BindingID      *string
-	SeToken        *string
-	PAN            *string
-	CVC            *string
-	Expiry         *string
-	CardHolderName *string
-	JSONParams     *JSONParams `json:"jsonParams,omitempty"`
+	UserName       string      `form:"userName,omitempty"`
+	Password       string      `form:"password,omitempty"`
+	Token          string      `form:"token,omitempty"`
+	Amount         int64       `form:"amount"`
+	Language       *string     `form:"language,omitempty"`
+	Currency       *int        `form:"currency,omitempty"`
+	OrderNumber    *string     `form:"orderNumber,omitempty"`
+	BindingID      *string     `form:"bindingId,omitempty"`
+	SeToken        *string     `form:"seToken,omitempty"`
+	PAN            *string     `form:"pan,omitempty"`
+	CVC            *string     `form:"cvc,omitempty"`
+	Expiry         *string     `form:"expire,omitempty"`
+	CardHolderName *string     `form:"cardHolderName,omitempty"`
+	JSONParams     *JSONParams `json:"jsonParams,omitempty" form:"jsonParams,omitempty"`
 }
 
 type JSONParams struct {
@@ -39,11 +44,15 @@ type JSONParams struct {
 }
 
 func (request InstantRefundRequest) Validate() error {
+	if request.Token != "" && (request.UserName != "" || request.Password != "") {
+		return acquiring.ErrMixedCredentials
+	}
+
 	return validation.ValidateStruct(&request,
 		validation.Field(&request.OrderNumber, validation.Required, validation.Length(1, 30)),
 		validation.Field(&request.Amount, validation.Required, validation.Min(1)),
-		validation.Field(&request.UserName, validation.Required),
-		validation.Field(&request.Password, validation.Required),
+		validation.Field(&request.UserName, validation.When(request.Password != "", validation.Required)),
+		validation.Field(&request.Password, validation.When(request.UserName != "", validation.Required)),
 	)
 }
 
@@ -61,65 +70,10 @@ func (c Client) InstantRefund(ctx context.Context, instantRefundRequest InstantR
 	if err := instantRefundRequest.Validate(); err != nil {
 		return nil, nil, err
 	}
-	var jsonParams, _ = json.Marshal(instantRefundRequest.JSONParams)
-
-	body := map[string]string{
-		"userName": instantRefundRequest.UserName,
-		"password": instantRefundRequest.Password,
-		"amount":   strconv.FormatInt(instantRefundRequest.Amount, 10),
-	}
-	if instantRefundRequest.Language != nil {
-		body["language"] = *instantRefundRequest.Language
-	}
-
-	if instantRefundRequest.Currency != nil {
-		body["currency"] = strconv.Itoa(*instantRefundRequest.Currency)
-	}
-
-	if instantRefundRequest.OrderNumber != nil {
-		body["orderNumber"] = *instantRefundRequest.OrderNumber
-	}
-
-	if instantRefundRequest.BindingID != nil {
-		body["bindingId"] = *instantRefundRequest.BindingID
-	}
-
-	if instantRefundRequest.SeToken != nil {
-		body["seToken"] = *instantRefundRequest.SeToken
-	}
-
-	if instantRefundRequest.PAN != nil {
-		body["pan"] = *instantRefundRequest.PAN
-	}
-	if instantRefundRequest.CVC != nil {
-		body["cvc"] = *instantRefundRequest.CVC
-	}
-
-	if instantRefundRequest.Expiry != nil {
-		body["expire"] = *instantRefundRequest.Expiry
-	}
-
-	if instantRefundRequest.CardHolderName != nil {
-		body["cardHolderName"] = *instantRefundRequest.CardHolderName
-	}
-
-	if instantRefundRequest.JSONParams != nil {
-		body["jsonParams"] = string(jsonParams)
-	}
-
-	var orderResponse schema.InstantRefundResponse
-	req, err := c.API.NewRestRequest(ctx, http.MethodPost, path, body, nil)
 
-	if err != nil {
-		return nil, nil, err
-	}
-	result, err := c.API.Do(req, &orderResponse)
-	if err != nil {
-		return nil, result, err
-	}
-	_ = json.NewDecoder(result.Body).Decode(&orderResponse)
+	op := acquiring.Operation[InstantRefundRequest, schema.InstantRefundResponse]{Method: http.MethodPost, Path: path}
 
-	return &orderResponse, result, err
+	return acquiring.Call(ctx, c.API, op, instantRefundRequest)
 }
 
 func getClient() Client {
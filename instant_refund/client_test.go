@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"testing"
 
 	acquiring "github.com/helios-ag/sberbank-acquiring-go"
@@ -35,8 +36,7 @@ func prepareClient(URL string) {
 		SessionTimeoutSecs: 1200,
 		SandboxMode:        true,
 	}
-	acquiring.SetConfig(cfg)
-	acquiring.WithEndpoint(URL)
+	acquiring.SetConfig(cfg, acquiring.WithEndpoint(URL))
 }
 
 func TestClient_InstantRefund(t *testing.T) {
@@ -166,4 +166,31 @@ func TestClient_InstantRefund(t *testing.T) {
 		// We don't care what underlying error happened
 		Expect(err).To(HaveOccurred())
 	})
+
+	t.Run("Client token is sent", func(t *testing.T) {
+		newServer := server.NewServer()
+		defer newServer.Teardown()
+		acquiring.SetConfig(acquiring.ClientConfig{SandboxMode: true}, acquiring.WithEndpoint(newServer.URL), acquiring.WithToken("merchant-token"))
+
+		var form url.Values
+		newServer.Mux.HandleFunc(endpoints.InstantRefund, func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			form = r.PostForm
+			fmt.Fprint(w, `{"errorCode":"0"}`)
+		})
+		orderNumber := "9231a838-ac68-4a3e"
+
+		_, _, err := InstantRefund(context.Background(), InstantRefundRequest{OrderNumber: &orderNumber, Amount: 1})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(form.Get("token")).To(Equal("merchant-token"))
+		Expect(form).ToNot(HaveKey("userName"))
+		Expect(form).ToNot(HaveKey("password"))
+
+		_, _, err = InstantRefund(context.Background(), InstantRefundRequest{OrderNumber: &orderNumber, Amount: 1, Token: "refund-token"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(form.Get("token")).To(Equal("refund-token"))
+
+		_, _, err = InstantRefund(context.Background(), InstantRefundRequest{OrderNumber: &orderNumber, Amount: 1, Token: "refund-token", UserName: "refund-api"})
+		Expect(err).To(MatchError(acquiring.ErrMixedCredentials))
+	})
 }
@@ -0,0 +1,180 @@
+package sberbank_acquiring_go
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// EndpointGroup is a group of endpoints sharing a Limit.
+type EndpointGroup string
+
+const (
+	// GroupStatus are read-only calls: order and receipt status, bindings, 3DS enrollment
+	GroupStatus EndpointGroup = "status"
+	// GroupPayment are calls that change orders and bindings: register, deposit, refund, payments, etc.
+	GroupPayment EndpointGroup = "payment"
+)
+
+// EndpointGroupOf returns the group of the endpoint.
+func EndpointGroupOf(endpoint string) EndpointGroup {
+	if readOnlyEndpoints[endpoint] {
+		return GroupStatus
+	}
+
+	return GroupPayment
+}
+
+// Limit restricts the rate and concurrency of requests. Zero values mean no restriction.
+type Limit struct {
+	// Rate is the number of requests per second
+	Rate float64
+	// Burst is the number of requests that can be sent at once, 1 when not set
+	Burst int
+	// MaxInFlight is the number of requests sent concurrently
+	MaxInFlight int
+}
+
+// WithLimit configures a Client to restrict all requests with the limit.
+// Clients derived with GetAPI share the limit of the default client.
+func WithLimit(limit Limit) ClientOption {
+	return withLimiter(newLimiter("", limit))
+}
+
+// WithEndpointGroupLimit configures a Client to restrict requests of the endpoint group,
+// in addition to the limit of WithLimit.
+//
+//	acquiring.WithEndpointGroupLimit(acquiring.GroupStatus, acquiring.Limit{Rate: 10, MaxInFlight: 4})
+func WithEndpointGroupLimit(group EndpointGroup, limit Limit) ClientOption {
+	return withLimiter(newLimiter(group, limit))
+}
+
+func withLimiter(l *limiter) ClientOption {
+	return func(c *Client) {
+		c.limiters = append(c.limiters[:len(c.limiters):len(c.limiters)], l)
+	}
+}
+
+// limiter is a token bucket with a semaphore.
+type limiter struct {
+	group EndpointGroup
+	slots chan struct{}
+
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newLimiter(group EndpointGroup, limit Limit) *limiter {
+	l := &limiter{
+		group: group,
+		rate:  limit.Rate,
+		burst: float64(max(limit.Burst, 1)),
+	}
+	l.tokens = l.burst
+	if limit.MaxInFlight > 0 {
+		l.slots = make(chan struct{}, limit.MaxInFlight)
+	}
+
+	return l
+}
+
+// reserve takes a token and returns the delay before the request can be sent.
+func (l *limiter) reserve(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.last.IsZero() {
+		l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	}
+	l.last = now
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// unreserve returns the token of a request that wasn't sent.
+func (l *limiter) unreserve() {
+	l.mu.Lock()
+	l.tokens = min(l.burst, l.tokens+1)
+	l.mu.Unlock()
+}
+
+// wait blocks until the request can be sent or ctx is done.
+func (l *limiter) wait(ctx context.Context) error {
+	if l.rate > 0 {
+		if delay := l.reserve(time.Now()); delay > 0 {
+			timer := time.NewTimer(delay)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				l.unreserve()
+				return ctx.Err()
+			}
+		}
+	}
+
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		case <-ctx.Done():
+			if l.rate > 0 {
+				l.unreserve()
+			}
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+func (l *limiter) release() {
+	if l.slots != nil {
+		<-l.slots
+	}
+}
+
+// cancel releases the slot and returns the token of a request that wasn't sent.
+func (l *limiter) cancel() {
+	l.release()
+	if l.rate > 0 {
+		l.unreserve()
+	}
+}
+
+// acquire waits for the limits of the endpoint and returns a function releasing them.
+func (c *Client) acquire(ctx context.Context, endpoint string) (func(), error) {
+	if len(c.limiters) == 0 {
+		return func() {}, nil
+	}
+
+	group := EndpointGroupOf(endpoint)
+	acquired := make([]*limiter, 0, len(c.limiters))
+	release := func() {
+		for _, l := range acquired {
+			l.release()
+		}
+	}
+	for _, l := range c.limiters {
+		if l.group != "" && l.group != group {
+			continue
+		}
+		if err := l.wait(ctx); err != nil {
+			// the request isn't sent, so limits acquired before are given back
+			for _, acquired := range acquired {
+				acquired.cancel()
+			}
+			return nil, fmt.Errorf("waiting for %s request slot: %w", group, err)
+		}
+		acquired = append(acquired, l)
+	}
+
+	return release, nil
+}
@@ -0,0 +1,186 @@
+package sberbank_acquiring_go
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/helios-ag/sberbank-acquiring-go/endpoints"
+	server "github.com/helios-ag/sberbank-acquiring-go/testing"
+	. "github.com/onsi/gomega"
+)
+
+func sendRequest(ctx context.Context, client *Client, endpoint string) error {
+	req, err := client.NewRestRequest(ctx, http.MethodPost, endpoint, map[string]string{"orderId": "42"}, nil)
+	if err != nil {
+		return err
+	}
+	_, err = client.Do(req, nil)
+
+	return err
+}
+
+func TestEndpointGroupOf(t *testing.T) {
+	RegisterTestingT(t)
+
+	Expect(EndpointGroupOf(endpoints.GetOrderStatusExtended)).To(Equal(GroupStatus))
+	Expect(EndpointGroupOf(endpoints.GetBindings)).To(Equal(GroupStatus))
+	Expect(EndpointGroupOf(endpoints.Deposit)).To(Equal(GroupPayment))
+	Expect(EndpointGroupOf(endpoints.ApplePay)).To(Equal(GroupPayment))
+}
+
+func TestLimiter_reserve(t *testing.T) {
+	RegisterTestingT(t)
+
+	l := newLimiter("", Limit{Rate: 10, Burst: 2})
+	now := time.Now()
+
+	Expect(l.reserve(now)).To(BeZero())
+	Expect(l.reserve(now)).To(BeZero())
+	Expect(l.reserve(now)).To(Equal(100 * time.Millisecond))
+	Expect(l.reserve(now)).To(Equal(200 * time.Millisecond))
+
+	l.unreserve()
+	Expect(l.reserve(now.Add(300 * time.Millisecond))).To(BeZero())
+	Expect(l.reserve(now.Add(10*time.Second))).To(BeZero(), "tokens are capped by burst")
+	Expect(l.reserve(now.Add(10 * time.Second))).To(BeZero())
+	Expect(l.reserve(now.Add(10 * time.Second))).To(Equal(100 * time.Millisecond))
+}
+
+func TestClient_acquire(t *testing.T) {
+	RegisterTestingT(t)
+
+	client, err := NewClient(testConfig,
+		WithLimit(Limit{Rate: 1, Burst: 1, MaxInFlight: 1}),
+		WithEndpointGroupLimit(GroupStatus, Limit{MaxInFlight: 1}))
+	Expect(err).ToNot(HaveOccurred())
+	global, status := client.limiters[0], client.limiters[1]
+
+	// the status group is busy, so status requests are rejected by the second limiter
+	status.slots <- struct{}{}
+	for i := 0; i < 3; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		_, err := client.acquire(ctx, endpoints.GetOrderStatusExtended)
+		cancel()
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+	}
+
+	Expect(global.slots).To(BeEmpty())
+	Expect(global.tokens).To(BeNumerically("~", 1, 0.1))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	release, err := client.acquire(ctx, endpoints.Deposit)
+	Expect(err).ToNot(HaveOccurred(), "rejected requests don't drain the global limit")
+	release()
+}
+
+func TestWithLimit(t *testing.T) {
+	RegisterTestingT(t)
+
+	testServer := server.NewServer()
+	defer testServer.Teardown()
+
+	var inFlight, maxInFlight atomic.Int32
+	unblock := make(chan struct{})
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			observed := maxInFlight.Load()
+			if current <= observed || maxInFlight.CompareAndSwap(observed, current) {
+				break
+			}
+		}
+		if r.URL.Query().Get("block") != "" {
+			<-unblock
+		}
+		time.Sleep(5 * time.Millisecond)
+		fmt.Fprint(w, `{"errorCode":"0"}`)
+	}
+	testServer.Mux.HandleFunc(endpoints.Deposit, handler)
+	testServer.Mux.HandleFunc(endpoints.GetOrderStatusExtended, handler)
+
+	t.Run("Rate", func(t *testing.T) {
+		client, err := NewClient(testConfig, WithEndpoint(testServer.URL), WithLimit(Limit{Rate: 20}))
+		Expect(err).ToNot(HaveOccurred())
+
+		start := time.Now()
+		for i := 0; i < 4; i++ {
+			Expect(sendRequest(context.Background(), client, endpoints.Deposit)).To(Succeed())
+		}
+		Expect(time.Since(start)).To(BeNumerically(">=", 140*time.Millisecond))
+	})
+
+	t.Run("Max in flight", func(t *testing.T) {
+		maxInFlight.Store(0)
+		client, err := NewClient(testConfig, WithEndpoint(testServer.URL), WithLimit(Limit{MaxInFlight: 2}))
+		Expect(err).ToNot(HaveOccurred())
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_ = sendRequest(context.Background(), client, endpoints.Deposit)
+			}()
+		}
+		wg.Wait()
+
+		Expect(maxInFlight.Load()).To(BeNumerically("<=", 2))
+	})
+
+	t.Run("Waiting honours context", func(t *testing.T) {
+		client, err := NewClient(testConfig, WithEndpoint(testServer.URL), WithLimit(Limit{MaxInFlight: 1}))
+		Expect(err).ToNot(HaveOccurred())
+
+		done := make(chan error)
+		go func() {
+			req, _ := http.NewRequest(http.MethodPost, testServer.URL+endpoints.Deposit+"?block=1", nil)
+			_, err := client.Do(req.WithContext(withEndpoint(context.Background(), endpoints.Deposit)), nil)
+			done <- err
+		}()
+		Eventually(inFlight.Load).Should(BeEquivalentTo(1))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		err = sendRequest(ctx, client, endpoints.Deposit)
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+		Expect(err).To(MatchError(ContainSubstring("waiting for payment request slot")))
+
+		close(unblock)
+		Expect(<-done).ToNot(HaveOccurred())
+	})
+
+	t.Run("Endpoint groups", func(t *testing.T) {
+		client, err := NewClient(testConfig, WithEndpoint(testServer.URL),
+			WithEndpointGroupLimit(GroupStatus, Limit{Rate: 10}))
+		Expect(err).ToNot(HaveOccurred())
+
+		start := time.Now()
+		for i := 0; i < 3; i++ {
+			Expect(sendRequest(context.Background(), client, endpoints.Deposit)).To(Succeed())
+		}
+		Expect(time.Since(start)).To(BeNumerically("<", 100*time.Millisecond), "payment group isn't limited")
+
+		Expect(sendRequest(context.Background(), client, endpoints.GetOrderStatusExtended)).To(Succeed())
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		Expect(sendRequest(ctx, client, endpoints.GetOrderStatusExtended)).To(MatchError(context.DeadlineExceeded))
+
+		Expect(sendRequest(context.Background(), client, endpoints.GetOrderStatusExtended)).To(Succeed())
+	})
+
+	t.Run("Default client shares limits", func(t *testing.T) {
+		SetConfig(testConfig, WithEndpoint(testServer.URL), WithLimit(Limit{MaxInFlight: 1}))
+		defer SetConfig(ClientConfig{})
+
+		derived := GetAPI(WithUserAgent("test")).(*Client)
+		Expect(derived.limiters).To(HaveLen(1))
+		Expect(derived.limiters[0]).To(BeIdenticalTo(GetAPI().(*Client).limiters[0]))
+	})
+}
@@ -0,0 +1,191 @@
+package sberbank_acquiring_go
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Redacted replaces values of secret parameters in logs.
+const Redacted = "[REDACTED]"
+
+// secretParams are parameters holding credentials and card data, compared case-insensitively.
+// maskedPan is not a secret and is kept.
+var secretParams = []string{"password", "token", "pan", "cvc", "seToken", "paymentToken"}
+
+func isSecret(key string) bool {
+	for _, secret := range secretParams {
+		if strings.EqualFold(key, secret) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RedactParams returns a copy of params with credentials and card data replaced by Redacted.
+// JSON values, e.g. orderBundle, are redacted as well.
+func RedactParams(params url.Values) url.Values {
+	redacted := make(url.Values, len(params))
+	for key, values := range params {
+		redacted[key] = make([]string, len(values))
+		for i, value := range values {
+			if isSecret(key) {
+				redacted[key][i] = Redacted
+			} else {
+				redacted[key][i] = string(RedactJSON([]byte(value)))
+			}
+		}
+	}
+
+	return redacted
+}
+
+// RedactJSON returns data with values of secret fields replaced by Redacted.
+// Data that is not a JSON object or array is returned as is.
+func RedactJSON(data []byte) []byte {
+	trimmed := strings.TrimSpace(string(data))
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+		return data
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return data
+	}
+	redacted, err := json.Marshal(redactValue(value))
+	if err != nil {
+		return data
+	}
+
+	return redacted
+}
+
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if isSecret(key) {
+				v[key] = Redacted
+			} else {
+				v[key] = redactValue(field)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactValue(item)
+		}
+	}
+
+	return value
+}
+
+// LogValue implements slog.LogValuer, so credentials are not logged with the config.
+func (c ClientConfig) LogValue() slog.Value {
+	attrs := []slog.Attr{
+		slog.String("userName", c.UserName),
+		slog.Int("currency", c.Currency),
+		slog.String("language", c.Language),
+		slog.Bool("sandbox", c.SandboxMode),
+	}
+	if c.Password != "" {
+		attrs = append(attrs, slog.String("password", Redacted))
+	}
+	if c.token != "" {
+		attrs = append(attrs, slog.String("token", Redacted))
+	}
+
+	return slog.GroupValue(attrs...)
+}
+
+// WithLogger configures a Client to log every call to the gateway, see LoggingMiddleware.
+func WithLogger(logger *slog.Logger) ClientOption {
+	return WithMiddleware(LoggingMiddleware(logger))
+}
+
+// LoggingMiddleware logs endpoint, duration, order, errorCode and HTTP status of every attempt.
+// Successful calls are logged with Info level, gateway errors with Warn and other errors with Error.
+// Redacted request params and response body are logged with Debug level.
+func LoggingMiddleware(logger *slog.Logger) Middleware {
+	return func(next Handler) Handler {
+		return func(req *Request) (*Response, error) {
+			start := time.Now()
+			resp, err := next(req)
+
+			attrs := []slog.Attr{
+				slog.String("endpoint", req.Endpoint),
+				slog.Int("attempt", req.Attempt),
+				slog.Duration("duration", time.Since(start)),
+			}
+			orderId, orderNumber := req.Params.Get("orderId"), req.Params.Get("orderNumber")
+			if resp != nil {
+				var body struct {
+					OrderId     string `json:"orderId"`
+					OrderNumber string `json:"orderNumber"`
+					Data        struct {
+						OrderId string `json:"orderId"`
+					} `json:"data"`
+				}
+				_ = json.Unmarshal(resp.Body, &body)
+				orderId = firstNonEmpty(orderId, body.OrderId, body.Data.OrderId)
+				orderNumber = firstNonEmpty(orderNumber, body.OrderNumber)
+			}
+			if orderId != "" {
+				attrs = append(attrs, slog.String("orderId", orderId))
+			}
+			if orderNumber != "" {
+				attrs = append(attrs, slog.String("orderNumber", orderNumber))
+			}
+			if resp != nil && resp.HTTP != nil {
+				attrs = append(attrs, slog.Int("status", resp.HTTP.StatusCode))
+			}
+
+			level := slog.LevelInfo
+			var apiErr *APIError
+			switch {
+			case errors.As(err, &apiErr):
+				level = slog.LevelWarn
+				attrs = append(attrs, slog.Int("errorCode", apiErr.ErrorCode), slog.String("errorMessage", apiErr.ErrorMessage))
+			case err != nil:
+				level = slog.LevelError
+				attrs = append(attrs, slog.String("error", err.Error()))
+			}
+
+			ctx := req.Context()
+			if logger.Enabled(ctx, slog.LevelDebug) {
+				params := RedactParams(req.Params)
+				keys := make([]string, 0, len(params))
+				for key := range params {
+					keys = append(keys, key)
+				}
+				sort.Strings(keys)
+				paramAttrs := make([]any, 0, len(keys))
+				for _, key := range keys {
+					paramAttrs = append(paramAttrs, slog.String(key, params.Get(key)))
+				}
+				attrs = append(attrs, slog.Group("params", paramAttrs...))
+				if resp != nil {
+					attrs = append(attrs, slog.String("response", string(RedactJSON(resp.Body))))
+				}
+			}
+
+			logger.LogAttrs(ctx, level, "sberbank request", attrs...)
+
+			return resp, err
+		}
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
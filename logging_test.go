@@ -0,0 +1,122 @@
+package sberbank_acquiring_go
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/helios-ag/sberbank-acquiring-go/endpoints"
+	server "github.com/helios-ag/sberbank-acquiring-go/testing"
+	. "github.com/onsi/gomega"
+)
+
+func TestLogging(t *testing.T) {
+	RegisterTestingT(t)
+
+	logRecords := func(level slog.Level, path string, status int, body string, data map[string]string) []map[string]interface{} {
+		testServer := server.NewServer()
+		defer testServer.Teardown()
+		testServer.Mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+			fmt.Fprint(w, body)
+		})
+
+		var buf bytes.Buffer
+		logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: level}))
+		client, err := NewClient(ClientConfig{UserName: "test-api", Password: "secret-password"}, WithEndpoint(testServer.URL), WithLogger(logger))
+		Expect(err).ToNot(HaveOccurred())
+
+		req, err := client.NewRestRequest(context.Background(), http.MethodPost, path, data, nil)
+		Expect(err).ToNot(HaveOccurred())
+		_, _ = client.Do(req, nil)
+
+		Expect(buf.String()).ToNot(ContainSubstring("secret-password"))
+
+		var records []map[string]interface{}
+		decoder := json.NewDecoder(&buf)
+		for decoder.More() {
+			var record map[string]interface{}
+			Expect(decoder.Decode(&record)).To(Succeed())
+			records = append(records, record)
+		}
+
+		return records
+	}
+
+	t.Run("Successful call", func(t *testing.T) {
+		records := logRecords(slog.LevelInfo, endpoints.Register, http.StatusOK, `{"orderId":"70906e55","formUrl":"https://form"}`, map[string]string{"orderNumber": "42"})
+
+		Expect(records).To(HaveLen(1))
+		Expect(records[0]).To(HaveKeyWithValue("level", "INFO"))
+		Expect(records[0]).To(HaveKeyWithValue("msg", "sberbank request"))
+		Expect(records[0]).To(HaveKeyWithValue("endpoint", endpoints.Register))
+		Expect(records[0]).To(HaveKeyWithValue("orderNumber", "42"))
+		Expect(records[0]).To(HaveKeyWithValue("orderId", "70906e55"))
+		Expect(records[0]).To(HaveKeyWithValue("status", BeNumerically("==", 200)))
+		Expect(records[0]).To(HaveKey("duration"))
+		Expect(records[0]).ToNot(HaveKey("params"))
+	})
+
+	t.Run("Gateway error", func(t *testing.T) {
+		records := logRecords(slog.LevelInfo, endpoints.Deposit, http.StatusOK, `{"errorCode":"6","errorMessage":"Заказ не найден"}`, map[string]string{"orderId": "70906e55"})
+
+		Expect(records).To(HaveLen(1))
+		Expect(records[0]).To(HaveKeyWithValue("level", "WARN"))
+		Expect(records[0]).To(HaveKeyWithValue("orderId", "70906e55"))
+		Expect(records[0]).To(HaveKeyWithValue("errorCode", BeNumerically("==", 6)))
+		Expect(records[0]).To(HaveKeyWithValue("errorMessage", "Заказ не найден"))
+	})
+
+	t.Run("Secrets are redacted in debug log", func(t *testing.T) {
+		records := logRecords(slog.LevelDebug, endpoints.InstantRefund, http.StatusOK,
+			`{"errorCode":"0","cardAuthInfo":{"maskedPan":"411111**1111","pan":"4111111111111111"}}`,
+			map[string]string{
+				"pan":     "4111111111111111",
+				"cvc":     "123",
+				"seToken": "se-token",
+				"amount":  "100",
+			})
+
+		Expect(records).To(HaveLen(1))
+		params := records[0]["params"].(map[string]interface{})
+		Expect(params).To(HaveKeyWithValue("userName", "test-api"))
+		Expect(params).To(HaveKeyWithValue("password", Redacted))
+		Expect(params).To(HaveKeyWithValue("pan", Redacted))
+		Expect(params).To(HaveKeyWithValue("cvc", Redacted))
+		Expect(params).To(HaveKeyWithValue("seToken", Redacted))
+		Expect(params).To(HaveKeyWithValue("amount", "100"))
+
+		response := records[0]["response"].(string)
+		Expect(response).To(ContainSubstring(`"maskedPan":"411111**1111"`))
+		Expect(response).ToNot(ContainSubstring("4111111111111111"))
+	})
+
+	t.Run("Redact nested JSON", func(t *testing.T) {
+		params := RedactParams(url.Values{
+			"token":   {"secret-token"},
+			"payment": {`{"paymentToken":"secret","items":[{"Token":"secret","maskedPan":"4111**1111"}]}`},
+			"amount":  {"100"},
+		})
+
+		Expect(params.Get("token")).To(Equal(Redacted))
+		Expect(params.Get("amount")).To(Equal("100"))
+		Expect(params.Get("payment")).ToNot(ContainSubstring(`"secret"`))
+		Expect(params.Get("payment")).To(ContainSubstring(`"maskedPan":"4111**1111"`))
+		Expect(string(RedactJSON([]byte("not json")))).To(Equal("not json"))
+	})
+
+	t.Run("Config is logged without credentials", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := slog.New(slog.NewJSONHandler(&buf, nil))
+		config := ClientConfig{UserName: "test-api", Password: "secret-password", token: "secret-token"}
+		logger.Info("config", "config", config)
+
+		Expect(buf.String()).To(ContainSubstring(`"userName":"test-api"`))
+		Expect(buf.String()).ToNot(ContainSubstring("secret"))
+	})
+}
@@ -0,0 +1,176 @@
+package sberbank_acquiring_go
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Request is a request to the API passed through the middleware chain.
+type Request struct {
+	// Endpoint is the API path of the request, e.g. endpoints.Register
+	Endpoint string
+	// Attempt is the number of the attempt, starting from 1
+	Attempt int
+	// Params are decoded from the request body: form values of REST requests
+	// and top-level fields of JSON requests. Changing them doesn't change the request.
+	Params url.Values
+	// HTTP is the request sent to the gateway
+	HTTP *http.Request
+}
+
+// Context returns context of the request.
+func (r *Request) Context() context.Context {
+	return r.HTTP.Context()
+}
+
+// Response is a response of the API passed through the middleware chain.
+type Response struct {
+	// HTTP is the response of the gateway, its Body can be read again
+	HTTP *http.Response
+	// Body is the raw response body
+	Body []byte
+}
+
+// Handler sends a request to the API. Errors reported by the gateway are returned as *APIError
+// together with the response.
+type Handler func(req *Request) (*Response, error)
+
+// Middleware wraps a Handler, e.g. to log requests or collect metrics.
+type Middleware func(next Handler) Handler
+
+// WithMiddleware configures a Client to pass every attempt of every request through middleware.
+// The first middleware is the outermost one.
+func WithMiddleware(middleware ...Middleware) ClientOption {
+	return func(c *Client) {
+		c.middleware = append(c.middleware[:len(c.middleware):len(c.middleware)], middleware...)
+	}
+}
+
+// handler returns the middleware chain ending with send.
+func (c *Client) handler() Handler {
+	handler := c.send
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		handler = c.middleware[i](handler)
+	}
+
+	return handler
+}
+
+// send performs the HTTP request and reads the response.
+func (c *Client) send(req *Request) (*Response, error) {
+	resp, err := c.httpClient.Do(req.HTTP)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := reader(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return &Response{HTTP: resp}, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	response := &Response{HTTP: resp, Body: body}
+
+	apiErr := errorFromResponse(resp, body)
+	if apiErr == nil && resp.StatusCode >= 400 && resp.StatusCode <= 599 {
+		apiErr = &APIError{
+			Endpoint:   req.Endpoint,
+			StatusCode: resp.StatusCode,
+			Body:       body,
+		}
+	}
+	if apiErr != nil {
+		return response, apiErr
+	}
+
+	return response, nil
+}
+
+// do performs a single attempt of the request, checking the circuit breaker and waiting for the limits of the client.
+func (c *Client) do(r *http.Request, params url.Values, attempt int, v interface{}) (*http.Response, error) {
+	done, err := c.enter()
+	if err != nil {
+		return nil, err
+	}
+
+	release, err := c.acquire(r.Context(), endpointOf(r))
+	if err != nil {
+		done(r.Context(), err)
+		return nil, err
+	}
+	defer release()
+
+	req := &Request{
+		Endpoint: endpointOf(r),
+		Attempt:  attempt,
+		Params:   params,
+		HTTP:     r,
+	}
+
+	response, err := c.handler()(req)
+	done(r.Context(), err)
+	if response == nil || response.HTTP == nil {
+		return nil, err
+	}
+
+	var apiErr *APIError
+	if v != nil && response.HTTP.StatusCode < 400 && (err == nil || errors.As(err, &apiErr)) {
+		var decodeErr error
+		if w, ok := v.(io.Writer); ok {
+			_, decodeErr = io.Copy(w, bytes.NewReader(response.Body))
+		} else {
+			decodeErr = json.Unmarshal(response.Body, v)
+		}
+		if decodeErr != nil {
+			return response.HTTP, decodeErr
+		}
+	}
+
+	return response.HTTP, err
+}
+
+// requestParams decodes form values of REST request or top-level fields of JSON request.
+func requestParams(r *http.Request) url.Values {
+	params := url.Values{}
+	if r.GetBody == nil {
+		return params
+	}
+
+	body, err := r.GetBody()
+	if err != nil {
+		return params
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return params
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	switch {
+	case strings.HasPrefix(contentType, "application/x-www-form-urlencoded"):
+		params, _ = url.ParseQuery(string(data))
+	case strings.HasPrefix(contentType, "application/json"):
+		var fields map[string]json.RawMessage
+		if json.Unmarshal(data, &fields) != nil {
+			return params
+		}
+		for key, value := range fields {
+			var s string
+			if json.Unmarshal(value, &s) == nil {
+				params.Set(key, s)
+			} else {
+				params.Set(key, string(value))
+			}
+		}
+	}
+
+	return params
+}
@@ -0,0 +1,179 @@
+package sberbank_acquiring_go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/helios-ag/sberbank-acquiring-go/endpoints"
+	server "github.com/helios-ag/sberbank-acquiring-go/testing"
+	. "github.com/onsi/gomega"
+)
+
+func TestMiddleware(t *testing.T) {
+	RegisterTestingT(t)
+
+	newClient := func(options ...ClientOption) (*Client, server.Server) {
+		testServer := server.NewServer()
+		testServer.Mux.HandleFunc(endpoints.Deposit, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Request-Tag", r.Header.Get("X-Request-Tag"))
+			fmt.Fprint(w, `{"errorCode":"6","errorMessage":"Заказ не найден"}`)
+		})
+		testServer.Mux.HandleFunc(endpoints.GetOrderStatusExtended, func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"errorCode":"0","orderNumber":"42"}`)
+		})
+		testServer.Mux.HandleFunc(endpoints.ApplePay, func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"success":true}`)
+		})
+
+		options = append([]ClientOption{WithEndpoint(testServer.URL)}, options...)
+		client, err := NewClient(ClientConfig{UserName: "test-api", Password: "test"}, options...)
+		Expect(err).ToNot(HaveOccurred())
+
+		return client, testServer
+	}
+
+	t.Run("Middleware sees endpoint, params and response", func(t *testing.T) {
+		var seen []string
+		var gotErr error
+		audit := func(next Handler) Handler {
+			return func(req *Request) (*Response, error) {
+				seen = append(seen, req.Endpoint, req.Params.Get("orderId"))
+				resp, err := next(req)
+				seen = append(seen, string(resp.Body))
+				gotErr = err
+				return resp, err
+			}
+		}
+		client, testServer := newClient(WithMiddleware(audit))
+		defer testServer.Teardown()
+
+		req, err := client.NewRestRequest(context.Background(), http.MethodPost, endpoints.Deposit, map[string]string{"orderId": "42"}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		var response struct {
+			ErrorCode int `json:"errorCode,string"`
+		}
+		_, err = client.Do(req, &response)
+
+		Expect(err).To(MatchError(ErrOrderNotFound))
+		Expect(gotErr).To(MatchError(ErrOrderNotFound))
+		Expect(response.ErrorCode).To(Equal(6))
+		Expect(seen).To(Equal([]string{endpoints.Deposit, "42", `{"errorCode":"6","errorMessage":"Заказ не найден"}`}))
+	})
+
+	t.Run("Middleware is called in order", func(t *testing.T) {
+		var calls []string
+		named := func(name string) Middleware {
+			return func(next Handler) Handler {
+				return func(req *Request) (*Response, error) {
+					calls = append(calls, name+" before")
+					resp, err := next(req)
+					calls = append(calls, name+" after")
+					return resp, err
+				}
+			}
+		}
+		client, testServer := newClient(WithMiddleware(named("first"), named("second")), WithMiddleware(named("third")))
+		defer testServer.Teardown()
+
+		req, _ := client.NewRestRequest(context.Background(), http.MethodPost, endpoints.GetOrderStatusExtended, nil, nil)
+		_, err := client.Do(req, nil)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(calls).To(Equal([]string{"first before", "second before", "third before", "third after", "second after", "first after"}))
+	})
+
+	t.Run("Middleware can change the request", func(t *testing.T) {
+		tag := func(next Handler) Handler {
+			return func(req *Request) (*Response, error) {
+				req.HTTP.Header.Set("X-Request-Tag", "audit")
+				return next(req)
+			}
+		}
+		client, testServer := newClient(WithMiddleware(tag))
+		defer testServer.Teardown()
+
+		req, _ := client.NewRestRequest(context.Background(), http.MethodPost, endpoints.Deposit, nil, nil)
+		resp, _ := client.Do(req, nil)
+
+		Expect(resp.Header.Get("X-Request-Tag")).To(Equal("audit"))
+	})
+
+	t.Run("JSON params are decoded", func(t *testing.T) {
+		var params map[string][]string
+		capture := func(next Handler) Handler {
+			return func(req *Request) (*Response, error) {
+				params = req.Params
+				return next(req)
+			}
+		}
+		client, testServer := newClient(WithMiddleware(capture))
+		defer testServer.Teardown()
+
+		req, _ := client.NewRequest(context.Background(), http.MethodPost, endpoints.ApplePay, map[string]interface{}{
+			"merchant":             "test",
+			"orderNumber":          "42",
+			"preAuth":              true,
+			"additionalParameters": map[string]string{"a": "b"},
+		})
+		_, err := client.Do(req, nil)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(params).To(HaveKeyWithValue("orderNumber", []string{"42"}))
+		Expect(params).To(HaveKeyWithValue("preAuth", []string{"true"}))
+		Expect(params).To(HaveKeyWithValue("additionalParameters", []string{`{"a":"b"}`}))
+	})
+
+	t.Run("Fault injection is retried", func(t *testing.T) {
+		var attempts []int
+		faulty := func(next Handler) Handler {
+			return func(req *Request) (*Response, error) {
+				attempts = append(attempts, req.Attempt)
+				if req.Attempt == 1 {
+					return nil, errors.New("injected fault")
+				}
+				return next(req)
+			}
+		}
+		client, testServer := newClient(
+			WithMiddleware(faulty),
+			WithRetryPolicy(RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}),
+		)
+		defer testServer.Teardown()
+
+		req, _ := client.NewRestRequest(context.Background(), http.MethodPost, endpoints.GetOrderStatusExtended, nil, nil)
+		var response struct {
+			OrderNumber string `json:"orderNumber"`
+		}
+		_, err := client.Do(req, &response)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(attempts).To(Equal([]int{1, 2}))
+		Expect(response.OrderNumber).To(Equal("42"))
+	})
+
+	t.Run("Options of default client keep middleware", func(t *testing.T) {
+		calls := 0
+		count := func(next Handler) Handler {
+			return func(req *Request) (*Response, error) {
+				calls++
+				return next(req)
+			}
+		}
+		client, testServer := newClient(WithMiddleware(count))
+		defer testServer.Teardown()
+
+		clone := client.clone()
+		clone.apply(WithMiddleware(count))
+		Expect(client.middleware).To(HaveLen(1))
+		Expect(clone.middleware).To(HaveLen(2))
+
+		req, _ := clone.NewRestRequest(context.Background(), http.MethodPost, endpoints.GetOrderStatusExtended, nil, nil)
+		_, err := clone.Do(req, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(calls).To(Equal(2))
+	})
+}
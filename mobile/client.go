@@ -2,7 +2,6 @@ package mobile
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/http"
 
@@ -15,6 +14,12 @@ type Client struct {
 	API acquiring.API
 }
 
+// NewClient creates a Client that sends requests through api,
+// usually a client created with acquiring.NewClient.
+func NewClient(api acquiring.API) Client {
+	return Client{API: api}
+}
+
 // ApplePaymentRequest is used for building PayWithApplePay request
 type ApplePaymentRequest struct {
 	OrderNumber          string `json:"orderNumber"`
@@ -40,20 +45,9 @@ func (c Client) PayWithApplePay(ctx context.Context, applePaymentRequest ApplePa
 		return nil, nil, err
 	}
 
-	var response schema.ApplePaymentResponse
-
-	req, err := c.API.NewRequest(ctx, http.MethodPost, path, applePaymentRequest)
-
-	if err != nil {
-		return nil, nil, err
-	}
-	result, err := c.API.Do(req, &response)
-	if err != nil {
-		return nil, result, err
-	}
-	_ = json.NewDecoder(result.Body).Decode(&response)
+	op := acquiring.Operation[ApplePaymentRequest, schema.ApplePaymentResponse]{Method: http.MethodPost, Path: path}
 
-	return &response, result, err
+	return acquiring.Call(ctx, c.API, op, applePaymentRequest)
 }
 
 func validateApplePaymentRequest(request ApplePaymentRequest) error {
@@ -107,20 +101,9 @@ func (c Client) PayWithGooglePay(ctx context.Context, googlePaymentRequest Googl
 		return nil, nil, err
 	}
 
-	var response schema.GooglePaymentResponse
-
-	req, err := c.API.NewRequest(ctx, http.MethodGet, path, googlePaymentRequest)
+	op := acquiring.Operation[GooglePaymentRequest, schema.GooglePaymentResponse]{Method: http.MethodGet, Path: path}
 
-	if err != nil {
-		return nil, nil, err
-	}
-	result, err := c.API.Do(req, &response)
-	if err != nil {
-		return nil, result, err
-	}
-	_ = json.NewDecoder(result.Body).Decode(&response)
-
-	return &response, result, err
+	return acquiring.Call(ctx, c.API, op, googlePaymentRequest)
 }
 
 func validateGooglePayRequest(request GooglePaymentRequest) error {
@@ -165,19 +148,9 @@ func (c Client) PayWithSamsungPay(ctx context.Context, samsungPaymentRequest Sam
 		return nil, nil, err
 	}
 
-	var response schema.SamsungPaymentResponse
-	req, err := c.API.NewRequest(ctx, http.MethodGet, path, samsungPaymentRequest)
+	op := acquiring.Operation[SamsungPaymentRequest, schema.SamsungPaymentResponse]{Method: http.MethodGet, Path: path}
 
-	if err != nil {
-		return nil, nil, err
-	}
-	result, err := c.API.Do(req, &response)
-	if err != nil {
-		return nil, result, err
-	}
-	_ = json.NewDecoder(result.Body).Decode(&response)
-
-	return &response, result, err
+	return acquiring.Call(ctx, c.API, op, samsungPaymentRequest)
 }
 
 // PayWithSamsungPayDirect is used to send PayWithSamsungPay request
@@ -189,19 +162,9 @@ func (c Client) PayWithSamsungPayDirect(ctx context.Context, samsungPaymentReque
 		return nil, nil, err
 	}
 
-	var response schema.SamsungPaymentResponse
-	req, err := c.API.NewRequest(ctx, http.MethodGet, path, samsungPaymentRequest)
+	op := acquiring.Operation[SamsungPaymentRequest, schema.SamsungPaymentResponse]{Method: http.MethodGet, Path: path}
 
-	if err != nil {
-		return nil, nil, err
-	}
-	result, err := c.API.Do(req, &response)
-	if err != nil {
-		return nil, result, err
-	}
-	_ = json.NewDecoder(result.Body).Decode(&response)
-
-	return &response, result, err
+	return acquiring.Call(ctx, c.API, op, samsungPaymentRequest)
 }
 
 // MirPayPaymentRequest используется для отправки запроса на /payment/mirpay
@@ -209,9 +172,11 @@ func (c Client) PayWithSamsungPayDirect(ctx context.Context, samsungPaymentReque
 // "Description" необязательное описание заказа, отображается на странице оплаты
 // "Language" необязательный язык страницы оплаты ("ru" или "en")
 // "ClientId" необязательный идентификатор клиента в системе мерчанта
+// "Token" необязательный токен мерчанта, передаётся вместо Username/Password
 type MirPayPaymentRequest struct {
-	Username             string            `json:"userName"`
-	Password             string            `json:"password"`
+	Username             string            `json:"userName,omitempty"`
+	Password             string            `json:"password,omitempty"`
+	Token                string            `json:"token,omitempty"`
 	Merchant             string            `json:"merchant"`
 	ClientId             string            `json:"clientId,omitempty"`
 	OrderNumber          string            `json:"orderNumber"`
@@ -238,19 +203,9 @@ func (c Client) PayWithMirPay(ctx context.Context, mirPaymentRequest MirPayPayme
 		return nil, nil, err
 	}
 
-	var response schema.MirPayPaymentResponse
-	req, err := c.API.NewRequest(ctx, http.MethodGet, path, mirPaymentRequest)
-
-	if err != nil {
-		return nil, nil, err
-	}
-	result, err := c.API.Do(req, &response)
-	if err != nil {
-		return nil, result, err
-	}
-	_ = json.NewDecoder(result.Body).Decode(&response)
+	op := acquiring.Operation[MirPayPaymentRequest, schema.MirPayPaymentResponse]{Method: http.MethodGet, Path: path}
 
-	return &response, result, err
+	return acquiring.Call(ctx, c.API, op, mirPaymentRequest)
 }
 
 // PayWithMirPayDirect request
@@ -267,25 +222,18 @@ func (c Client) PayWithMirPayDirect(ctx context.Context, mirPayPaymentRequest Mi
 		return nil, nil, err
 	}
 
-	var response schema.MirPayPaymentResponse
-	req, err := c.API.NewRequest(ctx, http.MethodGet, path, mirPayPaymentRequest)
+	op := acquiring.Operation[MirPayPaymentRequest, schema.MirPayPaymentResponse]{Method: http.MethodGet, Path: path}
 
-	if err != nil {
-		return nil, nil, err
-	}
-	result, err := c.API.Do(req, &response)
-	if err != nil {
-		return nil, result, err
-	}
-	_ = json.NewDecoder(result.Body).Decode(&response)
-
-	return &response, result, err
+	return acquiring.Call(ctx, c.API, op, mirPayPaymentRequest)
 }
 
 func validateMirPaymentRequest(request MirPayPaymentRequest) error {
 	if request.OrderNumber == "" || request.Merchant == "" || request.PaymentToken == "" || request.IP == "" {
 		return fmt.Errorf("orderNumber, merchant, IP and PaymentToken are required")
 	}
+	if request.Token != "" && (request.Username != "" || request.Password != "") {
+		return acquiring.ErrMixedCredentials
+	}
 	return nil
 }
 
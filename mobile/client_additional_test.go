@@ -19,8 +19,7 @@ func prepareTestClient(URL string) {
 		UserName: "test-api",
 		Password: "test",
 	}
-	acquiring.SetConfig(cfg)
-	acquiring.WithEndpoint(URL)
+	acquiring.SetConfig(cfg, acquiring.WithEndpoint(URL))
 }
 
 func TestAdditional_PayWithSamsungPay(t *testing.T) {
@@ -24,8 +24,7 @@ func prepareClient(URL string) {
 		SessionTimeoutSecs: 1200,
 		SandboxMode:        true,
 	}
-	acquiring.SetConfig(cfg)
-	acquiring.WithEndpoint(URL)
+	acquiring.SetConfig(cfg, acquiring.WithEndpoint(URL))
 }
 
 var NewRequestStub = func(
@@ -132,6 +131,31 @@ func TestClient_PayWithApplePay(t *testing.T) {
 		Expect(err).To(HaveOccurred())
 		acquiring.NewRequest = oldNewRequest
 	})
+
+	t.Run("Test ApplePaymentRequest dry run", func(t *testing.T) {
+		testServer := server.NewServer()
+		defer testServer.Teardown()
+		prepareClient(testServer.URL)
+
+		testServer.Mux.HandleFunc(endpoints.ApplePay, func(w http.ResponseWriter, r *http.Request) {
+			t.Error("request must not be sent")
+		})
+
+		req := ApplePaymentRequest{
+			OrderNumber:  "test",
+			Merchant:     "test",
+			PaymentToken: "secret",
+		}
+
+		prepared, err := acquiring.DryRun(context.Background(), func(ctx context.Context) error {
+			_, _, err := PayWithApplePay(ctx, req)
+			return err
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(prepared.URL).To(Equal(testServer.URL + endpoints.ApplePay))
+		Expect(prepared.Body()).To(ContainSubstring(`"orderNumber":"test"`))
+		Expect(prepared.Body()).ToNot(ContainSubstring("secret"))
+	})
 }
 
 func TestClient_PayWithGooglePay(t *testing.T) {
@@ -385,6 +409,36 @@ func TestClient_PayWithMirPay(t *testing.T) {
 		})))
 	})
 
+	t.Run("Test mir payment with token", func(t *testing.T) {
+		testServer := server.NewServer()
+		defer testServer.Teardown()
+		prepareClient(testServer.URL)
+
+		testServer.Mux.HandleFunc(endpoints.MirPay, func(w http.ResponseWriter, r *http.Request) {
+			var body map[string]interface{}
+			Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+			Expect(body).To(HaveKeyWithValue("token", "merchant-token"))
+			Expect(body).ToNot(HaveKey("userName"))
+			Expect(body).ToNot(HaveKey("password"))
+			json.NewEncoder(w).Encode(schema.MirPayPaymentResponse{Success: true})
+		})
+
+		req := MirPayPaymentRequest{
+			Token:        "merchant-token",
+			OrderNumber:  "test",
+			Merchant:     "test",
+			PaymentToken: "test",
+			IP:           "10.10.10.1",
+		}
+
+		_, _, err := PayWithMirPay(context.Background(), req)
+		Expect(err).ToNot(HaveOccurred())
+
+		req.Password = "test"
+		_, _, err = PayWithMirPay(context.Background(), req)
+		Expect(err).To(MatchError(acquiring.ErrMixedCredentials))
+	})
+
 	t.Run("Test MirPayment Do", func(t *testing.T) {
 		testServer := server.NewServer()
 		defer testServer.Teardown()
@@ -2,9 +2,10 @@ package orders
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"regexp"
 	"strconv"
 
@@ -20,6 +21,12 @@ type Client struct {
 	API acquiring.API
 }
 
+// NewClient creates a Client that sends requests through api,
+// usually a client created with acquiring.NewClient.
+func NewClient(api acquiring.API) Client {
+	return Client{API: api}
+}
+
 // Order is used to carry data related that passed to acquiring api requests.
 //
 // "OrderNumber" used to pass orderId to api
@@ -51,6 +58,61 @@ type Order struct {
 	JSONParams          map[string]string
 }
 
+// registerRequest is the body of register.do and registerPreAuth.do requests
+type registerRequest struct {
+	OrderNumber         string                  `form:"orderNumber"`
+	Amount              int                     `form:"amount"`
+	ReturnURL           string                  `form:"returnUrl"`
+	FailURL             string                  `form:"failUrl,omitempty"`
+	Description         string                  `form:"description,omitempty"`
+	PageView            string                  `form:"pageView,omitempty"`
+	MerchantLogin       string                  `form:"merchantLogin,omitempty"`
+	ExpirationDate      string                  `form:"expirationDate,omitempty"`
+	BindingID           string                  `form:"bindingId,omitempty"`
+	OrderBundle         OrderBundle             `form:"orderBundle,omitempty"`
+	AdditionalOfdParams ofd.AdditionalOfdParams `form:"additionalOfdParams,omitempty"`
+	Features            string                  `form:"features,omitempty"`
+	JSONParams          map[string]string       `form:"jsonParams,omitempty"`
+}
+
+// depositRequest is the body of deposit.do request, zero amount deposits the whole order
+type depositRequest struct {
+	OrderId    string            `form:"orderId"`
+	Amount     int               `form:"amount"`
+	JSONParams map[string]string `form:"jsonParams,omitempty"`
+}
+
+// reverseRequest is the body of reverse.do request
+type reverseRequest struct {
+	OrderId    string            `form:"orderId"`
+	JSONParams map[string]string `form:"jsonParams,omitempty"`
+}
+
+// refundRequest is the body of refund.do request
+type refundRequest struct {
+	OrderId      string            `form:"orderId"`
+	RefundAmount int               `form:"refundAmount"`
+	JSONParams   map[string]string `form:"jsonParams,omitempty"`
+}
+
+// OrderStatusRequest is the body of getOrderStatus.do and getOrderStatusExtended.do requests.
+// The order is identified by OrderId of the gateway or by OrderNumber of the merchant.
+type OrderStatusRequest struct {
+	OrderId       string            `form:"orderId,omitempty"`
+	OrderNumber   string            `form:"orderNumber,omitempty"`
+	MerchantLogin string            `form:"merchantLogin,omitempty"`
+	Language      string            `form:"language,omitempty"`
+	JSONParams    map[string]string `form:"jsonParams,omitempty"`
+}
+
+func (request OrderStatusRequest) Validate() error {
+	return validation.ValidateStruct(&request,
+		validation.Field(&request.OrderId, validation.When(request.OrderNumber == "", validation.Required.Error("orderId or orderNumber is required"))),
+		validation.Field(&request.OrderNumber, validation.Length(1, 30)),
+		validation.Field(&request.Language, validation.Length(2, 2)),
+	)
+}
+
 func (order Order) Validate() error {
 	return validation.ValidateStruct(&order,
 		validation.Field(&order.ReturnURL, validation.Required, is.URL),
@@ -274,35 +336,101 @@ func (c Client) RegisterOrderPreAuth(ctx context.Context, order Order) (*schema.
 	return orderResponse, result, err
 }
 
-func (c Client) register(ctx context.Context, path string, order Order) (*schema.OrderResponse, *http.Response, error) {
-	body := make(map[string]string)
-	var orderBundle, _ = json.Marshal(order.OrderBundle)
-	body["orderNumber"] = order.OrderNumber
-	body["amount"] = strconv.Itoa(order.Amount)
-	body["returnUrl"] = order.ReturnURL
-	body["failUrl"] = order.FailURL
-	body["description"] = order.Description
-	body["pageView"] = order.PageView
-	body["merchantLogin"] = order.MerchantLogin
-	body["expirationDate"] = order.ExpirationDate
-	body["bindingId"] = order.BindingID
-	body["orderBundle"] = string(orderBundle)
-	body["features"] = order.Features
-
-	req, err := c.API.NewRestRequest(ctx, http.MethodGet, path, body, order.JSONParams)
+// ErrOrderNotReusable is returned by RegisterOrderIdempotent when an order with the same number
+// is registered, but it is paid, declined or registered with a different amount.
+var ErrOrderNotReusable = errors.New("order with this number is registered and can't be reused")
 
-	if err != nil {
+// IdempotentRegistration configures RegisterOrderIdempotent
+type IdempotentRegistration struct {
+	// PreAuth registers the order with registerPreAuth.do instead of register.do
+	PreAuth bool
+	// FormURL, if set, builds the payment link of an order registered before,
+	// e.g. "https://securepayments.sberbank.ru/payment/merchants/shop/payment_ru.html?mdOrder=" + orderId
+	FormURL func(orderId string) string
+}
+
+// RegisteredOrder is the result of RegisterOrderIdempotent
+type RegisteredOrder struct {
+	OrderId string
+	// FormUrl is the payment link, it is empty for an order registered before unless IdempotentRegistration.FormURL is set
+	FormUrl string
+	// Existing tells that the order had been registered by an earlier request
+	Existing bool
+	// Status is the status of the existing order
+	Status *schema.OrderStatusResponse
+}
+
+// RegisterOrderIdempotent registers the order, see Client.RegisterOrderIdempotent
+func RegisterOrderIdempotent(ctx context.Context, order Order, registration IdempotentRegistration) (*RegisteredOrder, *http.Response, error) {
+	return getClient().RegisterOrderIdempotent(ctx, order, registration)
+}
+
+// RegisterOrderIdempotent registers the order and can be repeated with the same order number,
+// e.g. when the response of the first request was lost. If the gateway reports that an order with
+// this number is already registered, the order is found with getOrderStatusExtended by orderNumber
+// and returned when it is still waiting for payment and has the same amount. Otherwise the found order
+// is returned together with an error matching ErrOrderNotReusable.
+func (c Client) RegisterOrderIdempotent(ctx context.Context, order Order, registration IdempotentRegistration) (*RegisteredOrder, *http.Response, error) {
+	path := endpoints.Register
+	if registration.PreAuth {
+		path = endpoints.RegisterPreAuth
+	}
+
+	if err := order.Validate(); err != nil {
 		return nil, nil, err
 	}
 
-	var orderResponse schema.OrderResponse
-	result, err := c.API.Do(req, &orderResponse)
-	if err != nil {
+	orderResponse, result, err := c.register(ctx, path, order)
+	if err == nil {
+		return &RegisteredOrder{OrderId: orderResponse.OrderId, FormUrl: orderResponse.FormUrl}, result, nil
+	}
+	if !errors.Is(err, acquiring.ErrOrderAlreadyPaid) {
 		return nil, result, err
 	}
-	_ = json.NewDecoder(result.Body).Decode(&orderResponse)
 
-	return &orderResponse, result, err
+	status, result, err := c.orderStatus(ctx, OrderStatusRequest{OrderNumber: order.OrderNumber, MerchantLogin: order.MerchantLogin})
+	if err != nil {
+		return nil, result, fmt.Errorf("unable to find registered order %s: %w", order.OrderNumber, err)
+	}
+
+	registered := &RegisteredOrder{OrderId: status.Attribute("mdOrder"), Existing: true, Status: status}
+	if registered.OrderId == "" {
+		return registered, result, fmt.Errorf("orderId of registered order %s is unknown", order.OrderNumber)
+	}
+	if registration.FormURL != nil {
+		registered.FormUrl = registration.FormURL(registered.OrderId)
+	}
+
+	switch {
+	case status.OrderStatus != schema.OrderStatusRegistered:
+		return registered, result, fmt.Errorf("%w: order status is %d", ErrOrderNotReusable, status.OrderStatus)
+	case status.Amount != order.Amount:
+		return registered, result, fmt.Errorf("%w: amount is %d, not %d", ErrOrderNotReusable, status.Amount, order.Amount)
+	}
+
+	return registered, result, nil
+}
+
+func (c Client) register(ctx context.Context, path string, order Order) (*schema.OrderResponse, *http.Response, error) {
+	body := registerRequest{
+		OrderNumber:         order.OrderNumber,
+		Amount:              order.Amount,
+		ReturnURL:           order.ReturnURL,
+		FailURL:             order.FailURL,
+		Description:         order.Description,
+		PageView:            order.PageView,
+		MerchantLogin:       order.MerchantLogin,
+		ExpirationDate:      order.ExpirationDate,
+		BindingID:           order.BindingID,
+		OrderBundle:         order.OrderBundle,
+		AdditionalOfdParams: order.AdditionalOfdParams,
+		Features:            order.Features,
+		JSONParams:          order.JSONParams,
+	}
+
+	op := acquiring.Operation[registerRequest, schema.OrderResponse]{Method: http.MethodGet, Path: path}
+
+	return acquiring.Call(ctx, c.API, op, body)
 }
 
 // Deposit request
@@ -320,24 +448,15 @@ func (c Client) Deposit(ctx context.Context, order Order) (*schema.OrderResponse
 		return nil, nil, err
 	}
 
-	body := make(map[string]string)
-	body["orderId"] = order.OrderNumber
-	body["amount"] = strconv.Itoa(order.Amount)
-
-	var orderResponse schema.OrderResponse
-	req, err := c.API.NewRestRequest(ctx, http.MethodPost, path, body, order.JSONParams)
-
-	if err != nil {
-		return nil, nil, err
+	body := depositRequest{
+		OrderId:    order.OrderNumber,
+		Amount:     order.Amount,
+		JSONParams: order.JSONParams,
 	}
 
-	result, err := c.API.Do(req, &orderResponse)
-	if err != nil {
-		return nil, result, err
-	}
-	_ = json.NewDecoder(result.Body).Decode(&orderResponse)
+	op := acquiring.Operation[depositRequest, schema.OrderResponse]{Method: http.MethodPost, Path: path}
 
-	return &orderResponse, result, err
+	return acquiring.Call(ctx, c.API, op, body)
 }
 
 // ReverseOrder request
@@ -355,22 +474,14 @@ func (c Client) ReverseOrder(ctx context.Context, order Order) (*schema.OrderRes
 		return nil, nil, err
 	}
 
-	body := make(map[string]string)
-	body["orderId"] = order.OrderNumber
-
-	var orderResponse schema.OrderResponse
-	req, err := c.API.NewRestRequest(ctx, http.MethodGet, path, body, order.JSONParams)
-
-	if err != nil {
-		return nil, nil, err
-	}
-	result, err := c.API.Do(req, &orderResponse)
-	if err != nil {
-		return nil, result, err
+	body := reverseRequest{
+		OrderId:    order.OrderNumber,
+		JSONParams: order.JSONParams,
 	}
-	_ = json.NewDecoder(result.Body).Decode(&orderResponse)
 
-	return &orderResponse, result, err
+	op := acquiring.Operation[reverseRequest, schema.OrderResponse]{Method: http.MethodGet, Path: path}
+
+	return acquiring.Call(ctx, c.API, op, body)
 }
 
 // RefundOrder request
@@ -388,23 +499,15 @@ func (c Client) RefundOrder(ctx context.Context, order Order) (*schema.OrderResp
 		return nil, nil, err
 	}
 
-	body := make(map[string]string)
-	body["orderId"] = order.OrderNumber
-	body["refundAmount"] = strconv.Itoa(order.Amount)
-
-	var orderResponse schema.OrderResponse
-	req, err := c.API.NewRestRequest(ctx, http.MethodGet, path, body, order.JSONParams)
-
-	if err != nil {
-		return nil, nil, err
-	}
-	result, err := c.API.Do(req, &orderResponse)
-	if err != nil {
-		return nil, result, err
+	body := refundRequest{
+		OrderId:      order.OrderNumber,
+		RefundAmount: order.Amount,
+		JSONParams:   order.JSONParams,
 	}
-	_ = json.NewDecoder(result.Body).Decode(&orderResponse)
 
-	return &orderResponse, result, err
+	op := acquiring.Operation[refundRequest, schema.OrderResponse]{Method: http.MethodGet, Path: path}
+
+	return acquiring.Call(ctx, c.API, op, body)
 }
 
 func validateRefundOrder(order Order) error {
@@ -425,37 +528,128 @@ func validateRefundOrder(order Order) error {
 	return nil
 }
 
-// GetOrderStatus request
+// GetOrderStatus request, order.OrderNumber is sent as orderId
 // see https://securepayments.sberbank.ru/wiki/doku.php/integration:api:rest:requests:getorderstatusextended
+//
+// Deprecated: GetOrderStatus(ctx, Order{OrderNumber: id, JSONParams: params}) is replaced by
+// GetOrderStatusExtended(ctx, OrderStatusRequest{OrderId: id, JSONParams: params}) with the same response,
+// use OrderNumber of OrderStatusRequest to look the order up by the merchant order number.
 func GetOrderStatus(ctx context.Context, order Order) (*schema.OrderStatusResponse, *http.Response, error) {
 	return getClient().GetOrderStatus(ctx, order)
 }
 
-// GetOrderStatus request
+// GetOrderStatus request, order.OrderNumber is sent as orderId
 // see https://securepayments.sberbank.ru/wiki/doku.php/integration:api:rest:requests:getorderstatusextended
+//
+// Deprecated: GetOrderStatus(ctx, Order{OrderNumber: id, JSONParams: params}) is replaced by
+// GetOrderStatusExtended(ctx, OrderStatusRequest{OrderId: id, JSONParams: params}) with the same response,
+// use OrderNumber of OrderStatusRequest to look the order up by the merchant order number.
 func (c Client) GetOrderStatus(ctx context.Context, order Order) (*schema.OrderStatusResponse, *http.Response, error) {
-	path := endpoints.GetOrderStatusExtended
-
 	if err := validateOrderNumber(order); err != nil {
 		return nil, nil, err
 	}
 
-	body := make(map[string]string)
-	body["orderId"] = order.OrderNumber
+	return c.orderStatus(ctx, OrderStatusRequest{OrderId: order.OrderNumber, JSONParams: order.JSONParams})
+}
 
-	var orderResponse schema.OrderStatusResponse
-	req, err := c.API.NewRestRequest(ctx, http.MethodGet, path, body, order.JSONParams)
+// GetOrderStatusExtended request
+// see https://securepayments.sberbank.ru/wiki/doku.php/integration:api:rest:requests:getorderstatusextended
+func GetOrderStatusExtended(ctx context.Context, request OrderStatusRequest) (*schema.OrderStatusResponse, *http.Response, error) {
+	return getClient().GetOrderStatusExtended(ctx, request)
+}
 
-	if err != nil {
+// GetOrderStatusExtended request
+// see https://securepayments.sberbank.ru/wiki/doku.php/integration:api:rest:requests:getorderstatusextended
+func (c Client) GetOrderStatusExtended(ctx context.Context, request OrderStatusRequest) (*schema.OrderStatusResponse, *http.Response, error) {
+	if err := request.Validate(); err != nil {
 		return nil, nil, err
 	}
-	result, err := c.API.Do(req, &orderResponse)
-	if err != nil {
-		return nil, result, err
+
+	return c.orderStatus(ctx, request)
+}
+
+// GetOrderStatusBasic request, the order is looked up by OrderId or by OrderNumber and MerchantLogin
+// see https://securepayments.sberbank.ru/wiki/doku.php/integration:api:rest:requests:getorderstatus
+func GetOrderStatusBasic(ctx context.Context, request OrderStatusRequest) (*schema.OrderStatusBasicResponse, *http.Response, error) {
+	return getClient().GetOrderStatusBasic(ctx, request)
+}
+
+// GetOrderStatusBasic request, the order is looked up by OrderId or by OrderNumber and MerchantLogin
+// see https://securepayments.sberbank.ru/wiki/doku.php/integration:api:rest:requests:getorderstatus
+func (c Client) GetOrderStatusBasic(ctx context.Context, request OrderStatusRequest) (*schema.OrderStatusBasicResponse, *http.Response, error) {
+	if err := request.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	path := endpoints.GetOrderStatus
+
+	op := acquiring.Operation[OrderStatusRequest, schema.OrderStatusBasicResponse]{Method: http.MethodGet, Path: path}
+
+	return acquiring.Call(ctx, c.API, op, request)
+}
+
+func (c Client) orderStatus(ctx context.Context, body OrderStatusRequest) (*schema.OrderStatusResponse, *http.Response, error) {
+	path := endpoints.GetOrderStatusExtended
+
+	op := acquiring.Operation[OrderStatusRequest, schema.OrderStatusResponse]{Method: http.MethodGet, Path: path}
+
+	return acquiring.Call(ctx, c.API, op, body)
+}
+
+// Reconcile checks whether failed register, deposit, reverse or refund request has been performed
+// by the gateway. It is meant to be used as acquiring.RetryPolicy.Reconcile:
+//
+//	acquiring.WithRetryPolicy(acquiring.RetryPolicy{
+//		MaxAttempts:     3,
+//		BaseDelay:       time.Second,
+//		UnsafeEndpoints: []string{endpoints.Deposit, endpoints.Refund},
+//		Reconcile:       orders.Reconcile,
+//	})
+//
+// A refund is considered performed when the refunded amount of the order has grown by refundAmount.
+// The amount refunded before the request must be passed with WithRefundedAmount, otherwise
+// reconciliation of the refund fails and it isn't repeated.
+func Reconcile(ctx context.Context, api acquiring.API, endpoint string, params url.Values) (bool, error) {
+	c := NewClient(api)
+
+	switch endpoint {
+	case endpoints.Register, endpoints.RegisterPreAuth:
+		_, _, err := c.orderStatus(ctx, OrderStatusRequest{OrderNumber: params.Get("orderNumber"), MerchantLogin: params.Get("merchantLogin")})
+		if errors.Is(err, acquiring.ErrOrderNotFound) {
+			return false, nil
+		}
+
+		return err == nil, err
+	case endpoints.Deposit, endpoints.Reverse, endpoints.Refund:
+		status, _, err := c.orderStatus(ctx, OrderStatusRequest{OrderId: params.Get("orderId")})
+		if err != nil {
+			return false, err
+		}
+
+		switch endpoint {
+		case endpoints.Deposit:
+			return status.OrderStatus == schema.OrderStatusDeposited, nil
+		case endpoints.Reverse:
+			return status.OrderStatus == schema.OrderStatusReversed, nil
+		}
+		refunded, ok := ctx.Value(refundedAmountKey{}).(int)
+		if !ok {
+			return false, errors.New("amount refunded before the refund is unknown, see orders.WithRefundedAmount")
+		}
+		refundAmount, _ := strconv.Atoi(params.Get("refundAmount"))
+
+		return status.PaymentAmountInfo.RefundedAmount >= refunded+refundAmount, nil
 	}
-	_ = json.NewDecoder(result.Body).Decode(&orderResponse)
 
-	return &orderResponse, result, err
+	return false, fmt.Errorf("reconciliation of %s is not supported", endpoint)
+}
+
+type refundedAmountKey struct{}
+
+// WithRefundedAmount returns ctx for a refund request of the order that already has refunded amount,
+// e.g. PaymentAmountInfo.RefundedAmount of its status. Reconcile uses it to tell whether the refund is performed.
+func WithRefundedAmount(ctx context.Context, refunded int) context.Context {
+	return context.WithValue(ctx, refundedAmountKey{}, refunded)
 }
 
 func validateOrderNumber(order Order) error {
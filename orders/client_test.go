@@ -4,7 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"strings"
 	"testing"
 
 	acquiring "github.com/helios-ag/sberbank-acquiring-go"
@@ -35,8 +38,7 @@ func prepareClient(URL string) {
 		SessionTimeoutSecs: 1200,
 		SandboxMode:        true,
 	}
-	acquiring.SetConfig(cfg)
-	acquiring.WithEndpoint(URL)
+	acquiring.SetConfig(cfg, acquiring.WithEndpoint(URL))
 }
 
 func TestClient_RegisterOrder(t *testing.T) {
@@ -138,6 +140,67 @@ func TestClient_RegisterOrder(t *testing.T) {
 		})))
 
 	})
+
+	t.Run("Dry run", func(t *testing.T) {
+		newServer := server.NewServer()
+		defer newServer.Teardown()
+		prepareClient(newServer.URL)
+
+		newServer.Mux.HandleFunc(endpoints.Register, func(w http.ResponseWriter, r *http.Request) {
+			t.Error("request must not be sent")
+		})
+
+		order := Order{
+			OrderNumber: "1234567890123456",
+			Amount:      100,
+			ReturnURL:   "https://localhost",
+		}
+
+		prepared, err := acquiring.DryRun(context.Background(), func(ctx context.Context) error {
+			_, _, err := RegisterOrder(ctx, order)
+			return err
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(prepared.URL).To(Equal(newServer.URL + endpoints.Register))
+		Expect(prepared.Form.Get("orderNumber")).To(Equal("1234567890123456"))
+		Expect(prepared.Form.Get("password")).To(Equal(acquiring.Redacted))
+
+		_, err = acquiring.DryRun(context.Background(), func(ctx context.Context) error {
+			_, _, err := RegisterOrder(ctx, Order{OrderNumber: "123", Amount: 100})
+			return err
+		})
+		Expect(err).To(MatchError(ContainSubstring("ReturnURL: cannot be blank")))
+	})
+}
+
+type recordingAPI struct {
+	acquiring.API
+	paths []string
+}
+
+func (r *recordingAPI) NewRestRequest(ctx context.Context, method, urlPath string, data map[string]string, jsonParams map[string]string) (*http.Request, error) {
+	r.paths = append(r.paths, urlPath)
+	return r.API.NewRestRequest(ctx, method, urlPath, data, jsonParams)
+}
+
+func TestNewClient(t *testing.T) {
+	RegisterTestingT(t)
+	t.Run("Client sends requests through given API", func(t *testing.T) {
+		newServer := server.NewServer()
+		defer newServer.Teardown()
+		prepareClient(newServer.URL)
+
+		newServer.Mux.HandleFunc(endpoints.Deposit, func(w http.ResponseWriter, r *http.Request) {
+			json.NewEncoder(w).Encode(schema.OrderResponse{})
+		})
+
+		api := &recordingAPI{API: acquiring.GetAPI()}
+		client := NewClient(api)
+
+		_, _, err := client.Deposit(context.Background(), Order{OrderNumber: "123", Amount: 100})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(api.paths).To(ConsistOf(endpoints.Deposit))
+	})
 }
 
 func TestClient_RegisterPreAuthOrder(t *testing.T) {
@@ -240,6 +303,132 @@ func TestClient_RegisterPreAuthOrder(t *testing.T) {
 	})
 }
 
+func TestClient_RegisterOrderIdempotent(t *testing.T) {
+	RegisterTestingT(t)
+
+	order := Order{
+		OrderNumber: "1234567890",
+		Amount:      100,
+		ReturnURL:   "https://localhost",
+	}
+	formURL := func(orderId string) string {
+		return "https://securepayments.sberbank.ru/payment/merchants/shop/payment_ru.html?mdOrder=" + orderId
+	}
+	serve := func(status string) server.Server {
+		newServer := server.NewServer()
+		prepareClient(newServer.URL)
+
+		newServer.Mux.HandleFunc(endpoints.RegisterPreAuth, func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"errorCode":"1","errorMessage":"Заказ с таким номером уже обработан"}`)
+		})
+		newServer.Mux.HandleFunc(endpoints.GetOrderStatusExtended, func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			params, _ := url.ParseQuery(string(body))
+			Expect(params.Get("orderNumber")).To(Equal("1234567890"))
+			Expect(params).ToNot(HaveKey("orderId"))
+			fmt.Fprint(w, status)
+		})
+
+		return newServer
+	}
+
+	t.Run("New order is registered", func(t *testing.T) {
+		newServer := server.NewServer()
+		defer newServer.Teardown()
+		prepareClient(newServer.URL)
+
+		newServer.Mux.HandleFunc(endpoints.Register, func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"orderId":"70906e55","formUrl":"https://form"}`)
+		})
+
+		registered, _, err := RegisterOrderIdempotent(context.Background(), order, IdempotentRegistration{FormURL: formURL})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(registered).To(Equal(&RegisteredOrder{OrderId: "70906e55", FormUrl: "https://form"}))
+	})
+
+	t.Run("Existing order is reused", func(t *testing.T) {
+		newServer := serve(`{"errorCode":"0","orderNumber":"1234567890","orderStatus":0,"amount":100,"attributes":[{"name":"mdOrder","value":"70906e55"}]}`)
+		defer newServer.Teardown()
+
+		registered, result, err := RegisterOrderIdempotent(context.Background(), order, IdempotentRegistration{PreAuth: true, FormURL: formURL})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Request.URL.Path).To(Equal(endpoints.GetOrderStatusExtended))
+		Expect(registered).To(PointTo(MatchFields(IgnoreExtras, Fields{
+			"OrderId":  Equal("70906e55"),
+			"FormUrl":  Equal("https://securepayments.sberbank.ru/payment/merchants/shop/payment_ru.html?mdOrder=70906e55"),
+			"Existing": BeTrue(),
+			"Status":   PointTo(MatchFields(IgnoreExtras, Fields{"OrderNumber": Equal("1234567890")})),
+		})))
+	})
+
+	t.Run("Sub-merchant order is found under its merchant", func(t *testing.T) {
+		newServer := server.NewServer()
+		defer newServer.Teardown()
+		prepareClient(newServer.URL)
+
+		newServer.Mux.HandleFunc(endpoints.RegisterPreAuth, func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"errorCode":"1","errorMessage":"Заказ с таким номером уже обработан"}`)
+		})
+		var merchantLogin string
+		newServer.Mux.HandleFunc(endpoints.GetOrderStatusExtended, func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			params, _ := url.ParseQuery(string(body))
+			merchantLogin = params.Get("merchantLogin")
+			fmt.Fprint(w, `{"errorCode":"0","orderNumber":"1234567890","orderStatus":0,"amount":100,"attributes":[{"name":"mdOrder","value":"70906e55"}]}`)
+		})
+
+		subMerchantOrder := order
+		subMerchantOrder.MerchantLogin = "seller-1"
+		registered, _, err := RegisterOrderIdempotent(context.Background(), subMerchantOrder, IdempotentRegistration{PreAuth: true})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(registered.OrderId).To(Equal("70906e55"))
+		Expect(merchantLogin).To(Equal("seller-1"))
+	})
+
+	t.Run("Paid order is not reusable", func(t *testing.T) {
+		newServer := serve(`{"errorCode":"0","orderNumber":"1234567890","orderStatus":2,"amount":100,"attributes":[{"name":"mdOrder","value":"70906e55"}]}`)
+		defer newServer.Teardown()
+
+		registered, _, err := RegisterOrderIdempotent(context.Background(), order, IdempotentRegistration{PreAuth: true})
+		Expect(err).To(MatchError(ErrOrderNotReusable))
+		Expect(err).To(MatchError(ContainSubstring("order status is 2")))
+		Expect(registered.OrderId).To(Equal("70906e55"))
+		Expect(registered.FormUrl).To(BeEmpty())
+		Expect(registered.Status.OrderStatus).To(Equal(schema.OrderStatusDeposited))
+	})
+
+	t.Run("Order with other amount is not reusable", func(t *testing.T) {
+		newServer := serve(`{"errorCode":"0","orderNumber":"1234567890","orderStatus":0,"amount":500,"attributes":[{"name":"mdOrder","value":"70906e55"}]}`)
+		defer newServer.Teardown()
+
+		_, _, err := RegisterOrderIdempotent(context.Background(), order, IdempotentRegistration{PreAuth: true})
+		Expect(err).To(MatchError(ErrOrderNotReusable))
+		Expect(err).To(MatchError(ContainSubstring("amount is 500, not 100")))
+	})
+
+	t.Run("Status request fails", func(t *testing.T) {
+		newServer := serve(`{"errorCode":"6","errorMessage":"Заказ не найден"}`)
+		defer newServer.Teardown()
+
+		registered, _, err := RegisterOrderIdempotent(context.Background(), order, IdempotentRegistration{PreAuth: true})
+		Expect(err).To(MatchError(acquiring.ErrOrderNotFound))
+		Expect(registered).To(BeNil())
+	})
+
+	t.Run("Other errors are returned", func(t *testing.T) {
+		newServer := server.NewServer()
+		defer newServer.Teardown()
+		prepareClient(newServer.URL)
+
+		newServer.Mux.HandleFunc(endpoints.Register, func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"errorCode":"5","errorMessage":"Доступ запрещён"}`)
+		})
+
+		_, _, err := RegisterOrderIdempotent(context.Background(), order, IdempotentRegistration{})
+		Expect(err).To(MatchError(acquiring.ErrAccessDenied))
+	})
+}
+
 func TestClient_register(t *testing.T) {
 	RegisterTestingT(t)
 	//t.Run("Trigger register error on NewRequest", func(t *testing.T) {
@@ -281,6 +470,33 @@ func TestClient_register(t *testing.T) {
 		Expect(err).To(HaveOccurred())
 	})
 
+	t.Run("Unset parameters are not sent", func(t *testing.T) {
+		newServer := server.NewServer()
+		defer newServer.Teardown()
+		prepareClient(newServer.URL)
+
+		var params url.Values
+		newServer.Mux.HandleFunc(endpoints.Register, func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			params, _ = url.ParseQuery(string(body))
+			fmt.Fprint(w, `{"orderId":"70906e55","formUrl":"https://form"}`)
+		})
+		order := Order{
+			OrderNumber: "1234567890123456",
+			Amount:      100,
+			ReturnURL:   "https://localhost",
+			JSONParams:  map[string]string{"email": "test@example.com"},
+		}
+		_, _, err := Client{API: acquiring.GetAPI()}.register(context.Background(), endpoints.Register, order)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(params.Get("orderNumber")).To(Equal("1234567890123456"))
+		Expect(params.Get("amount")).To(Equal("100"))
+		Expect(params.Get("jsonParams")).To(MatchJSON(`{"email":"test@example.com"}`))
+		for _, name := range []string{"bindingId", "features", "expirationDate", "failUrl", "merchantLogin", "orderBundle", "additionalOfdParams"} {
+			Expect(params).ToNot(HaveKey(name))
+		}
+	})
 }
 
 func TestClient_Deposit(t *testing.T) {
@@ -629,6 +845,27 @@ func TestClient_GetOrderStatus(t *testing.T) {
 		Expect(err.Error()).To(ContainSubstring("404"))
 	})
 
+	t.Run("GetOrderStatus sends jsonParams", func(t *testing.T) {
+		newServer := server.NewServer()
+		defer newServer.Teardown()
+		prepareClient(newServer.URL)
+
+		newServer.Mux.HandleFunc(endpoints.GetOrderStatusExtended, func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			params, _ := url.ParseQuery(string(body))
+			Expect(params.Get("orderId")).To(Equal("9231a838-ac68-4a3e"))
+			Expect(params.Get("jsonParams")).To(MatchJSON(`{"source":"legacy"}`))
+			fmt.Fprint(w, `{"errorCode":"0","orderStatus":2}`)
+		})
+
+		order := Order{
+			OrderNumber: "9231a838-ac68-4a3e",
+			JSONParams:  map[string]string{"source": "legacy"},
+		}
+		_, _, err := GetOrderStatus(context.Background(), order)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
 	t.Run("Test GetOrderStatus NewRequest", func(t *testing.T) {
 		newServer := server.NewServer()
 		defer newServer.Teardown()
@@ -688,6 +925,261 @@ func TestClient_GetOrderStatus(t *testing.T) {
 	})
 }
 
+func TestClient_GetOrderStatusExtended(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Validate order status request", func(t *testing.T) {
+		_, _, err := GetOrderStatusExtended(context.Background(), OrderStatusRequest{})
+		Expect(err).To(MatchError(ContainSubstring("orderId or orderNumber is required")))
+
+		_, _, err = GetOrderStatusExtended(context.Background(), OrderStatusRequest{OrderNumber: "1234567890123456789012345678901"})
+		Expect(err).To(MatchError(ContainSubstring("OrderNumber: the length must be between 1 and 30")))
+	})
+
+	t.Run("Order is looked up by merchant orderNumber", func(t *testing.T) {
+		newServer := server.NewServer()
+		defer newServer.Teardown()
+		prepareClient(newServer.URL)
+
+		newServer.Mux.HandleFunc(endpoints.GetOrderStatusExtended, func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			params, _ := url.ParseQuery(string(body))
+			Expect(params.Get("orderNumber")).To(Equal("1234567890"))
+			Expect(params.Get("merchantLogin")).To(Equal("shop"))
+			Expect(params.Get("language")).To(Equal("en"))
+			Expect(params).ToNot(HaveKey("orderId"))
+			fmt.Fprint(w, `{"errorCode":"0","orderNumber":"1234567890","orderStatus":2,"amount":100}`)
+		})
+
+		status, _, err := GetOrderStatusExtended(context.Background(), OrderStatusRequest{OrderNumber: "1234567890", MerchantLogin: "shop", Language: "en"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(status).To(PointTo(MatchFields(IgnoreExtras, Fields{
+			"OrderNumber": Equal("1234567890"),
+			"OrderStatus": Equal(schema.OrderStatusDeposited),
+			"Amount":      Equal(100),
+		})))
+	})
+
+	t.Run("Order is looked up by orderId", func(t *testing.T) {
+		newServer := server.NewServer()
+		defer newServer.Teardown()
+		prepareClient(newServer.URL)
+
+		newServer.Mux.HandleFunc(endpoints.GetOrderStatusExtended, func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			params, _ := url.ParseQuery(string(body))
+			Expect(params.Get("orderId")).To(Equal("70906e55"))
+			Expect(params.Get("language")).To(Equal("ru"))
+			Expect(params).ToNot(HaveKey("orderNumber"))
+			fmt.Fprint(w, `{"errorCode":"6","errorMessage":"Заказ не найден"}`)
+		})
+
+		_, _, err := GetOrderStatusExtended(context.Background(), OrderStatusRequest{OrderId: "70906e55"})
+		Expect(err).To(MatchError(acquiring.ErrOrderNotFound))
+	})
+}
+
+func TestClient_GetOrderStatusBasic(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Validate order status request", func(t *testing.T) {
+		_, _, err := GetOrderStatusBasic(context.Background(), OrderStatusRequest{})
+		Expect(err).To(MatchError(ContainSubstring("orderId or orderNumber is required")))
+	})
+
+	t.Run("Order is looked up by orderNumber", func(t *testing.T) {
+		newServer := server.NewServer()
+		defer newServer.Teardown()
+		prepareClient(newServer.URL)
+
+		newServer.Mux.HandleFunc(endpoints.GetOrderStatus, func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			params, _ := url.ParseQuery(string(body))
+			Expect(params.Has("orderId")).To(BeFalse())
+			Expect(params.Get("orderNumber")).To(Equal("1234567890"))
+			Expect(params.Get("merchantLogin")).To(Equal("shop"))
+			Expect(params.Get("jsonParams")).To(MatchJSON(`{"source":"poller"}`))
+			fmt.Fprint(w, `{"ErrorCode":"0","OrderStatus":2,"OrderNumber":"1234567890"}`)
+		})
+
+		status, _, err := GetOrderStatusBasic(context.Background(), OrderStatusRequest{
+			OrderNumber:   "1234567890",
+			MerchantLogin: "shop",
+			JSONParams:    map[string]string{"source": "poller"},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(status.OrderNumber).To(Equal("1234567890"))
+	})
+
+	t.Run("Test GetOrderStatusBasic response mapping", func(t *testing.T) {
+		newServer := server.NewServer()
+		defer newServer.Teardown()
+		prepareClient(newServer.URL)
+
+		newServer.Mux.HandleFunc(endpoints.GetOrderStatus, func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			params, _ := url.ParseQuery(string(body))
+			Expect(params.Get("orderId")).To(Equal("70906e55"))
+			fmt.Fprint(w, `{"depositAmount":100,"currency":"643","authCode":2,"ErrorCode":"0","ErrorMessage":"Успешно","OrderStatus":2,"OrderNumber":"1234567890","Pan":"411111**1111","expiration":202512,"cardholderName":"CARDHOLDER","Amount":100,"approvalCode":"123456","Ip":"127.0.0.1"}`)
+		})
+
+		status, _, err := GetOrderStatusBasic(context.Background(), OrderStatusRequest{OrderId: "70906e55"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(status).To(Equal(&schema.OrderStatusBasicResponse{
+			OrderNumber:    "1234567890",
+			OrderStatus:    2,
+			ErrorMessage:   "Успешно",
+			Pan:            "411111**1111",
+			Expiration:     202512,
+			CardholderName: "CARDHOLDER",
+			Amount:         100,
+			DepositAmount:  100,
+			Currency:       643,
+			ApprovalCode:   "123456",
+			AuthCode:       2,
+			Ip:             "127.0.0.1",
+		}))
+	})
+
+	t.Run("Declined order is returned", func(t *testing.T) {
+		newServer := server.NewServer()
+		defer newServer.Teardown()
+		prepareClient(newServer.URL)
+
+		newServer.Mux.HandleFunc(endpoints.GetOrderStatus, func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"ErrorCode":"2","ErrorMessage":"Платёж отклонён","OrderStatus":6,"OrderNumber":"1234567890","Amount":100}`)
+		})
+
+		status, _, err := GetOrderStatusBasic(context.Background(), OrderStatusRequest{OrderId: "70906e55"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(status).ToNot(BeNil())
+		Expect(status.OrderStatus).To(Equal(schema.OrderStatusDeclined))
+		Expect(status.ErrorCode).To(Equal(2))
+		Expect(status.OrderNumber).To(Equal("1234567890"))
+	})
+
+	t.Run("Errors are mapped", func(t *testing.T) {
+		newServer := server.NewServer()
+		defer newServer.Teardown()
+		prepareClient(newServer.URL)
+
+		newServer.Mux.HandleFunc(endpoints.GetOrderStatus, func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"ErrorCode":"6","ErrorMessage":"Незарегистрированный OrderId"}`)
+		})
+
+		_, _, err := GetOrderStatusBasic(context.Background(), OrderStatusRequest{OrderId: "70906e55"})
+		Expect(err).To(MatchError(acquiring.ErrOrderNotFound))
+	})
+}
+
+func TestReconcile(t *testing.T) {
+	RegisterTestingT(t)
+
+	serve := func(status string) server.Server {
+		newServer := server.NewServer()
+		prepareClient(newServer.URL)
+
+		newServer.Mux.HandleFunc(endpoints.GetOrderStatusExtended, func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			params, _ := url.ParseQuery(string(body))
+			if params.Get("orderNumber") == "missing" || params.Get("merchantLogin") == "other" {
+				fmt.Fprint(w, `{"errorCode":"6","errorMessage":"Заказ не найден"}`)
+				return
+			}
+			fmt.Fprint(w, status)
+		})
+
+		return newServer
+	}
+
+	t.Run("Registered order is found by number", func(t *testing.T) {
+		newServer := serve(`{"errorCode":"0","orderNumber":"123","orderStatus":0}`)
+		defer newServer.Teardown()
+
+		applied, err := Reconcile(context.Background(), acquiring.GetAPI(), endpoints.Register, url.Values{"orderNumber": {"123"}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(applied).To(BeTrue())
+
+		applied, err = Reconcile(context.Background(), acquiring.GetAPI(), endpoints.Register, url.Values{"orderNumber": {"missing"}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(applied).To(BeFalse())
+
+		applied, err = Reconcile(context.Background(), acquiring.GetAPI(), endpoints.RegisterPreAuth, url.Values{"orderNumber": {"123"}, "merchantLogin": {"other"}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(applied).To(BeFalse())
+	})
+
+	t.Run("Deposit is checked by order status", func(t *testing.T) {
+		newServer := serve(`{"errorCode":"0","orderStatus":2}`)
+		defer newServer.Teardown()
+
+		applied, err := Reconcile(context.Background(), acquiring.GetAPI(), endpoints.Deposit, url.Values{"orderId": {"42"}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(applied).To(BeTrue())
+
+		applied, err = Reconcile(context.Background(), acquiring.GetAPI(), endpoints.Reverse, url.Values{"orderId": {"42"}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(applied).To(BeFalse())
+	})
+
+	t.Run("Refund is checked by refunded amount", func(t *testing.T) {
+		newServer := serve(`{"errorCode":"0","orderStatus":4,"paymentAmountInfo":{"refundedAmount":100}}`)
+		defer newServer.Teardown()
+
+		ctx := WithRefundedAmount(context.Background(), 0)
+		applied, err := Reconcile(ctx, acquiring.GetAPI(), endpoints.Refund, url.Values{"orderId": {"42"}, "refundAmount": {"100"}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(applied).To(BeTrue())
+
+		applied, err = Reconcile(ctx, acquiring.GetAPI(), endpoints.Refund, url.Values{"orderId": {"42"}, "refundAmount": {"200"}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(applied).To(BeFalse())
+	})
+
+	t.Run("Earlier partial refund isn't taken for the new one", func(t *testing.T) {
+		newServer := serve(`{"errorCode":"0","orderStatus":4,"paymentAmountInfo":{"refundedAmount":500}}`)
+		defer newServer.Teardown()
+
+		applied, err := Reconcile(WithRefundedAmount(context.Background(), 500), acquiring.GetAPI(), endpoints.Refund, url.Values{"orderId": {"42"}, "refundAmount": {"300"}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(applied).To(BeFalse())
+
+		applied, err = Reconcile(WithRefundedAmount(context.Background(), 200), acquiring.GetAPI(), endpoints.Refund, url.Values{"orderId": {"42"}, "refundAmount": {"300"}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(applied).To(BeTrue())
+
+		_, err = Reconcile(context.Background(), acquiring.GetAPI(), endpoints.Refund, url.Values{"orderId": {"42"}, "refundAmount": {"300"}})
+		Expect(err).To(MatchError(ContainSubstring("amount refunded before the refund is unknown")))
+	})
+
+	t.Run("Refund after partial one is repeated", func(t *testing.T) {
+		newServer := serve(`{"errorCode":"0","orderStatus":4,"paymentAmountInfo":{"refundedAmount":500}}`)
+		defer newServer.Teardown()
+		acquiring.SetConfig(acquiring.ClientConfig{UserName: "test-api", Password: "test", SandboxMode: true},
+			acquiring.WithEndpoint(newServer.URL),
+			acquiring.WithRetryPolicy(acquiring.RetryPolicy{MaxAttempts: 2, UnsafeEndpoints: []string{endpoints.Refund}, Reconcile: Reconcile}))
+
+		attempts := 0
+		newServer.Mux.HandleFunc(endpoints.Refund, func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			if attempts == 1 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			fmt.Fprint(w, `{"errorCode":"0","errorMessage":"Успешно"}`)
+		})
+
+		_, _, err := RefundOrder(WithRefundedAmount(context.Background(), 500), Order{OrderNumber: "42", Amount: 300})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(attempts).To(Equal(2))
+	})
+
+	t.Run("Unsupported endpoint", func(t *testing.T) {
+		_, err := Reconcile(context.Background(), acquiring.GetAPI(), endpoints.BindCard, url.Values{})
+		Expect(err).To(MatchError(ContainSubstring("is not supported")))
+	})
+}
+
 func TestClient_ValidateOrder(t *testing.T) {
 	RegisterTestingT(t)
 	t.Run("Test order validator pass", func(t *testing.T) {
@@ -758,3 +1250,31 @@ func TestClient_ValidateOrder(t *testing.T) {
 		Expect(err.Error()).To(ContainSubstring("OrderNumber: the length must be between 1 and 30"))
 	})
 }
+
+type staticTransport string
+
+func (s staticTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(string(s))),
+		Request:    r,
+	}, nil
+}
+
+func BenchmarkClient_GetOrderStatus(b *testing.B) {
+	body := `{"errorCode":"0","orderNumber":"42","orderStatus":2,"actionCode":0,"amount":100,"currency":643,"date":"1617972370000","cardAuthInfo":{"maskedPan":"411111**1111","expiration":203012,"cardholderName":"CARDHOLDER NAME","approvalCode":"123456","paymentSystem":"VISA"},"paymentAmountInfo":{"paymentState":"DEPOSITED","approvedAmount":100,"depositedAmount":100,"refundedAmount":0}}`
+	api, err := acquiring.NewClient(acquiring.ClientConfig{UserName: "test-api", Password: "test"},
+		acquiring.WithHTTPClient(&http.Client{Transport: staticTransport(body)}))
+	if err != nil {
+		b.Fatal(err)
+	}
+	client := NewClient(api)
+	order := Order{OrderNumber: "70906e55-7114-41d6"}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := client.GetOrderStatus(context.Background(), order); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	acquiring "github.com/helios-ag/sberbank-acquiring-go"
+	"github.com/helios-ag/sberbank-acquiring-go/endpoints"
+	"github.com/helios-ag/sberbank-acquiring-go/schema"
+)
+
+// MaxLastOrdersPageSize is the largest page of getLastOrdersForMerchants.do request
+const MaxLastOrdersPageSize = 200
+
+// lastOrdersTimeLayout is the format of from and to parameters, in Moscow time
+const lastOrdersTimeLayout = "20060102150405"
+
+var moscow = time.FixedZone("MSK", 3*60*60)
+
+// LastOrdersRequest selects orders listed by getLastOrdersForMerchants.do request
+type LastOrdersRequest struct {
+	// From and To limit the payment time of the orders, or the registration time with SearchByCreatedDate
+	From time.Time
+	To   time.Time
+	// TransactionStates are the states of the orders to list, all states by default
+	TransactionStates []schema.PaymentState
+	// Merchants are logins of the merchants to list orders of, the own merchant by default
+	Merchants           []string
+	SearchByCreatedDate bool
+	// Size is the number of orders on a page, MaxLastOrdersPageSize by default
+	Size int
+	// Page is the first page to read, pages are numbered from 0
+	Page     int
+	Language string
+}
+
+// lastOrdersRequest is the body of getLastOrdersForMerchants.do request
+type lastOrdersRequest struct {
+	Size                int    `form:"size"`
+	Page                int    `form:"page"`
+	From                string `form:"from"`
+	To                  string `form:"to"`
+	TransactionStates   string `form:"transactionStates"`
+	Merchants           string `form:"merchants,omitempty"`
+	SearchByCreatedDate bool   `form:"searchByCreatedDate,omitempty"`
+	Language            string `form:"language,omitempty"`
+}
+
+// paymentStates are the transactionStates of orders listed by default
+var paymentStates = []schema.PaymentState{
+	schema.PaymentStateCreated,
+	schema.PaymentStateApproved,
+	schema.PaymentStateDeposited,
+	schema.PaymentStateDeclined,
+	schema.PaymentStateReversed,
+	schema.PaymentStateRefunded,
+}
+
+func (request LastOrdersRequest) Validate() error {
+	return validation.ValidateStruct(&request,
+		validation.Field(&request.From, validation.Required),
+		validation.Field(&request.To, validation.Required, validation.By(func(interface{}) error {
+			if !request.From.IsZero() && !request.To.After(request.From) {
+				return errors.New("must be after from")
+			}
+			return nil
+		})),
+		validation.Field(&request.TransactionStates, validation.Each(validation.By(knownPaymentState))),
+		validation.Field(&request.Size, validation.Min(0), validation.Max(MaxLastOrdersPageSize)),
+		validation.Field(&request.Page, validation.Min(0)),
+	)
+}
+
+func knownPaymentState(value interface{}) error {
+	if state, _ := value.(schema.PaymentState); !slices.Contains(paymentStates, state) {
+		return errors.New("must be a valid value")
+	}
+
+	return nil
+}
+
+func (request LastOrdersRequest) body() lastOrdersRequest {
+	size := request.Size
+	if size == 0 {
+		size = MaxLastOrdersPageSize
+	}
+
+	states := request.TransactionStates
+	if len(states) == 0 {
+		states = paymentStates
+	}
+	transactionStates := make([]string, len(states))
+	for i, state := range states {
+		transactionStates[i] = string(state)
+	}
+
+	return lastOrdersRequest{
+		Size:                size,
+		Page:                request.Page,
+		From:                request.From.In(moscow).Format(lastOrdersTimeLayout),
+		To:                  request.To.In(moscow).Format(lastOrdersTimeLayout),
+		TransactionStates:   strings.Join(transactionStates, ","),
+		Merchants:           strings.Join(request.Merchants, ","),
+		SearchByCreatedDate: request.SearchByCreatedDate,
+		Language:            request.Language,
+	}
+}
+
+// GetLastOrdersForMerchants request returns a page of orders
+// see https://securepayments.sberbank.ru/wiki/doku.php/integration:api:rest:requests:getlastordersformerchants
+func GetLastOrdersForMerchants(ctx context.Context, request LastOrdersRequest) (*schema.LastOrdersResponse, *http.Response, error) {
+	return getClient().GetLastOrdersForMerchants(ctx, request)
+}
+
+// GetLastOrdersForMerchants request returns a page of orders
+// see https://securepayments.sberbank.ru/wiki/doku.php/integration:api:rest:requests:getlastordersformerchants
+func (c Client) GetLastOrdersForMerchants(ctx context.Context, request LastOrdersRequest) (*schema.LastOrdersResponse, *http.Response, error) {
+	if err := request.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	path := endpoints.GetLastOrdersForMerchants
+
+	op := acquiring.Operation[lastOrdersRequest, schema.LastOrdersResponse]{Method: http.MethodPost, Path: path}
+
+	return acquiring.Call(ctx, c.API, op, request.body())
+}
+
+// LastOrdersForMerchants returns orders of all pages starting from request.Page.
+func LastOrdersForMerchants(ctx context.Context, request LastOrdersRequest) iter.Seq2[schema.OrderSummary, error] {
+	return getClient().LastOrdersForMerchants(ctx, request)
+}
+
+// LastOrdersForMerchants returns orders of all pages starting from request.Page, the next page
+// is requested when the orders of the previous one are consumed. The first error stops iteration:
+//
+//	for order, err := range orders.LastOrdersForMerchants(ctx, orders.LastOrdersRequest{From: from, To: to}) {
+//		if err != nil {
+//			return err
+//		}
+//		reconcile(order.OrderNumber, order.OrderStatus, order.Amount)
+//	}
+func (c Client) LastOrdersForMerchants(ctx context.Context, request LastOrdersRequest) iter.Seq2[schema.OrderSummary, error] {
+	return func(yield func(schema.OrderSummary, error) bool) {
+		size := request.body().Size
+		for {
+			page, _, err := c.GetLastOrdersForMerchants(ctx, request)
+			if err != nil {
+				yield(schema.OrderSummary{}, err)
+				return
+			}
+
+			for _, order := range page.OrderStatuses {
+				if !yield(order, nil) {
+					return
+				}
+			}
+
+			if len(page.OrderStatuses) < size || (request.Page+1)*size >= page.TotalCount {
+				return
+			}
+			request.Page++
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package orders
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/helios-ag/sberbank-acquiring-go/endpoints"
+	"github.com/helios-ag/sberbank-acquiring-go/schema"
+	server "github.com/helios-ag/sberbank-acquiring-go/testing"
+	. "github.com/onsi/gomega"
+)
+
+func TestLastOrdersForMerchants(t *testing.T) {
+	RegisterTestingT(t)
+
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+
+	// serve lists total orders numbered from 1, recording the requested pages
+	serve := func(total int, pages *[]url.Values) server.Server {
+		newServer := server.NewServer()
+		prepareClient(newServer.URL)
+
+		newServer.Mux.HandleFunc(endpoints.GetLastOrdersForMerchants, func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			params, _ := url.ParseQuery(string(body))
+			*pages = append(*pages, params)
+
+			page, _ := strconv.Atoi(params.Get("page"))
+			size, _ := strconv.Atoi(params.Get("size"))
+			var orders []string
+			for n := page*size + 1; n <= min((page+1)*size, total); n++ {
+				orders = append(orders, fmt.Sprintf(`{"orderNumber":"%d","orderStatus":2,"amount":100,"currency":"643","attributes":[{"name":"mdOrder","value":"md-%d"}],"paymentAmountInfo":{"paymentState":"DEPOSITED"}}`, n, n))
+			}
+			fmt.Fprintf(w, `{"errorCode":"0","errorMessage":"Успешно","orderStatuses":[%s],"totalCount":%d,"page":%d,"pageSize":%d}`,
+				strings.Join(orders, ","), total, page, size)
+		})
+
+		return newServer
+	}
+
+	t.Run("Validate request", func(t *testing.T) {
+		_, _, err := GetLastOrdersForMerchants(context.Background(), LastOrdersRequest{})
+		Expect(err).To(MatchError(ContainSubstring("From: cannot be blank")))
+
+		_, _, err = GetLastOrdersForMerchants(context.Background(), LastOrdersRequest{From: to, To: from})
+		Expect(err).To(MatchError(ContainSubstring("To: must be after from")))
+
+		_, _, err = GetLastOrdersForMerchants(context.Background(), LastOrdersRequest{From: from, To: to, Size: 201, TransactionStates: []schema.PaymentState{"PAID"}})
+		Expect(err).To(MatchError(ContainSubstring("Size: must be no greater than 200")))
+		Expect(err).To(MatchError(ContainSubstring("TransactionStates: (0: must be a valid value.)")))
+	})
+
+	t.Run("Request parameters", func(t *testing.T) {
+		var pages []url.Values
+		newServer := serve(1, &pages)
+		defer newServer.Teardown()
+
+		page, _, err := GetLastOrdersForMerchants(context.Background(), LastOrdersRequest{
+			From:                from,
+			To:                  to,
+			TransactionStates:   []schema.PaymentState{schema.PaymentStateDeposited, schema.PaymentStateRefunded},
+			Merchants:           []string{"shop-1", "shop-2"},
+			SearchByCreatedDate: true,
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(page.TotalCount).To(Equal(1))
+		Expect(page.OrderStatuses[0].OrderId()).To(Equal("md-1"))
+		Expect(page.OrderStatuses[0].Currency).To(Equal(643))
+		Expect(page.OrderStatuses[0].PaymentAmountInfo.PaymentState).To(Equal(schema.PaymentStateDeposited))
+
+		Expect(pages[0].Get("from")).To(Equal("20240301030000"))
+		Expect(pages[0].Get("to")).To(Equal("20240302030000"))
+		Expect(pages[0].Get("transactionStates")).To(Equal("DEPOSITED,REFUNDED"))
+		Expect(pages[0].Get("merchants")).To(Equal("shop-1,shop-2"))
+		Expect(pages[0].Get("searchByCreatedDate")).To(Equal("true"))
+		Expect(pages[0].Get("size")).To(Equal("200"))
+		Expect(pages[0].Get("page")).To(Equal("0"))
+
+		pages = nil
+		_, _, err = GetLastOrdersForMerchants(context.Background(), LastOrdersRequest{From: from, To: to})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pages[0].Get("transactionStates")).To(Equal("CREATED,APPROVED,DEPOSITED,DECLINED,REVERSED,REFUNDED"))
+		Expect(pages[0]).ToNot(HaveKey("merchants"))
+		Expect(pages[0]).ToNot(HaveKey("searchByCreatedDate"))
+	})
+
+	t.Run("All pages are walked", func(t *testing.T) {
+		var pages []url.Values
+		newServer := serve(7, &pages)
+		defer newServer.Teardown()
+
+		var numbers []string
+		for order, err := range LastOrdersForMerchants(context.Background(), LastOrdersRequest{From: from, To: to, Size: 3}) {
+			Expect(err).ToNot(HaveOccurred())
+			numbers = append(numbers, order.OrderNumber)
+		}
+
+		Expect(numbers).To(Equal([]string{"1", "2", "3", "4", "5", "6", "7"}))
+		Expect(pages).To(HaveLen(3))
+		Expect(pages[2].Get("page")).To(Equal("2"))
+	})
+
+	t.Run("Full last page doesn't request an empty one", func(t *testing.T) {
+		var pages []url.Values
+		newServer := serve(6, &pages)
+		defer newServer.Teardown()
+
+		count := 0
+		for _, err := range LastOrdersForMerchants(context.Background(), LastOrdersRequest{From: from, To: to, Size: 3, Page: 1}) {
+			Expect(err).ToNot(HaveOccurred())
+			count++
+		}
+
+		Expect(count).To(Equal(3))
+		Expect(pages).To(HaveLen(1))
+	})
+
+	t.Run("Iteration stops early", func(t *testing.T) {
+		var pages []url.Values
+		newServer := serve(7, &pages)
+		defer newServer.Teardown()
+
+		for order := range LastOrdersForMerchants(context.Background(), LastOrdersRequest{From: from, To: to, Size: 3}) {
+			if order.OrderNumber == "2" {
+				break
+			}
+		}
+
+		Expect(pages).To(HaveLen(1))
+	})
+
+	t.Run("Errors stop iteration", func(t *testing.T) {
+		newServer := server.NewServer()
+		defer newServer.Teardown()
+		prepareClient(newServer.URL)
+
+		newServer.Mux.HandleFunc(endpoints.GetLastOrdersForMerchants, func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"errorCode":"5","errorMessage":"Доступ запрещён"}`)
+		})
+
+		var errs []error
+		for _, err := range LastOrdersForMerchants(context.Background(), LastOrdersRequest{From: from, To: to}) {
+			errs = append(errs, err)
+		}
+
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0]).To(MatchError(ContainSubstring("Доступ запрещён")))
+	})
+}
@@ -0,0 +1,78 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"unicode/utf8"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	acquiring "github.com/helios-ag/sberbank-acquiring-go"
+	"github.com/helios-ag/sberbank-acquiring-go/endpoints"
+	"github.com/helios-ag/sberbank-acquiring-go/schema"
+)
+
+// Limits of merchant order parameters accepted by the gateway, in characters
+const (
+	MaxOrderParamNameLength  = 255
+	MaxOrderParamValueLength = 1024
+)
+
+// OrderParams are merchant parameters of an order, they are returned in merchantOrderParams
+// of the order status, see schema.OrderStatusResponse.MerchantParams.
+type OrderParams map[string]string
+
+func (params OrderParams) Validate() error {
+	errs := validation.Errors{}
+	for name, value := range params {
+		switch {
+		case name == "":
+			return errors.New("parameter name can't be empty")
+		case utf8.RuneCountInString(name) > MaxOrderParamNameLength:
+			errs[name] = fmt.Errorf("name is longer than %d characters", MaxOrderParamNameLength)
+		case value == "":
+			errs[name] = errors.New("value can't be empty")
+		case utf8.RuneCountInString(value) > MaxOrderParamValueLength:
+			errs[name] = fmt.Errorf("value is longer than %d characters", MaxOrderParamValueLength)
+		}
+	}
+
+	return errs.Filter()
+}
+
+// AddParamsRequest is the body of addParams.do request
+type AddParamsRequest struct {
+	OrderId  string      `form:"orderId"`
+	Params   OrderParams `form:"params"`
+	Language string      `form:"language,omitempty"`
+}
+
+func (request AddParamsRequest) Validate() error {
+	return validation.ValidateStruct(&request,
+		validation.Field(&request.OrderId, validation.Required),
+		validation.Field(&request.Params, validation.Required),
+	)
+}
+
+// AddParams request adds merchant parameters to a registered order, e.g. a shipment ID after checkout.
+// Parameters with the same names are replaced.
+// see https://securepayments.sberbank.ru/wiki/doku.php/integration:api:rest:requests:addparams
+func AddParams(ctx context.Context, request AddParamsRequest) (*schema.Response, *http.Response, error) {
+	return getClient().AddParams(ctx, request)
+}
+
+// AddParams request adds merchant parameters to a registered order, e.g. a shipment ID after checkout.
+// Parameters with the same names are replaced.
+// see https://securepayments.sberbank.ru/wiki/doku.php/integration:api:rest:requests:addparams
+func (c Client) AddParams(ctx context.Context, request AddParamsRequest) (*schema.Response, *http.Response, error) {
+	if err := request.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	path := endpoints.AddParams
+
+	op := acquiring.Operation[AddParamsRequest, schema.Response]{Method: http.MethodPost, Path: path}
+
+	return acquiring.Call(ctx, c.API, op, request)
+}
@@ -0,0 +1,80 @@
+package orders
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	acquiring "github.com/helios-ag/sberbank-acquiring-go"
+	"github.com/helios-ag/sberbank-acquiring-go/endpoints"
+	server "github.com/helios-ag/sberbank-acquiring-go/testing"
+	. "github.com/onsi/gomega"
+)
+
+func TestClient_AddParams(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Validate params", func(t *testing.T) {
+		_, _, err := AddParams(context.Background(), AddParamsRequest{Params: OrderParams{"shipmentId": "42"}})
+		Expect(err).To(MatchError(ContainSubstring("OrderId: cannot be blank")))
+
+		_, _, err = AddParams(context.Background(), AddParamsRequest{OrderId: "70906e55"})
+		Expect(err).To(MatchError(ContainSubstring("Params: cannot be blank")))
+
+		_, _, err = AddParams(context.Background(), AddParamsRequest{OrderId: "70906e55", Params: OrderParams{"": "42"}})
+		Expect(err).To(MatchError(ContainSubstring("parameter name can't be empty")))
+
+		_, _, err = AddParams(context.Background(), AddParamsRequest{OrderId: "70906e55", Params: OrderParams{
+			strings.Repeat("n", MaxOrderParamNameLength+1): "42",
+			"comment":  strings.Repeat("ж", MaxOrderParamValueLength+1),
+			"empty":    "",
+			"delivery": strings.Repeat("ж", MaxOrderParamValueLength),
+		}})
+		Expect(err).To(MatchError(ContainSubstring("name is longer than 255 characters")))
+		Expect(err).To(MatchError(ContainSubstring("comment: value is longer than 1024 characters")))
+		Expect(err).To(MatchError(ContainSubstring("empty: value can't be empty")))
+		Expect(err).ToNot(MatchError(ContainSubstring("delivery")))
+	})
+
+	t.Run("Params are added and read back", func(t *testing.T) {
+		newServer := server.NewServer()
+		defer newServer.Teardown()
+		prepareClient(newServer.URL)
+
+		var added url.Values
+		newServer.Mux.HandleFunc(endpoints.AddParams, func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			added, _ = url.ParseQuery(string(body))
+			fmt.Fprint(w, `{"errorCode":"0","errorMessage":"Успешно"}`)
+		})
+		newServer.Mux.HandleFunc(endpoints.GetOrderStatusExtended, func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"errorCode":"0","orderNumber":"1234567890","merchantOrderParams":[{"name":"shipmentId","value":"SHP-42"},{"name":"carrier","value":"СДЭК"}]}`)
+		})
+
+		_, _, err := AddParams(context.Background(), AddParamsRequest{OrderId: "70906e55", Params: OrderParams{"shipmentId": "SHP-42", "carrier": "СДЭК"}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(added.Get("orderId")).To(Equal("70906e55"))
+		Expect(added.Get("params")).To(MatchJSON(`{"shipmentId":"SHP-42","carrier":"СДЭК"}`))
+
+		status, _, err := GetOrderStatusExtended(context.Background(), OrderStatusRequest{OrderId: "70906e55"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(status.MerchantParams()).To(Equal(map[string]string{"shipmentId": "SHP-42", "carrier": "СДЭК"}))
+	})
+
+	t.Run("Errors are mapped", func(t *testing.T) {
+		newServer := server.NewServer()
+		defer newServer.Teardown()
+		prepareClient(newServer.URL)
+
+		newServer.Mux.HandleFunc(endpoints.AddParams, func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"errorCode":"6","errorMessage":"Заказ не найден"}`)
+		})
+
+		_, _, err := AddParams(context.Background(), AddParamsRequest{OrderId: "70906e55", Params: OrderParams{"shipmentId": "SHP-42"}})
+		Expect(err).To(MatchError(acquiring.ErrOrderNotFound))
+	})
+}
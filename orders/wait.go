@@ -0,0 +1,158 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"net"
+	"time"
+
+	acquiring "github.com/helios-ag/sberbank-acquiring-go"
+	"github.com/helios-ag/sberbank-acquiring-go/schema"
+)
+
+// Clock schedules status requests of WaitForFinalStatus, tests may replace it to poll without waiting.
+type Clock interface {
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// WaitOptions configures polling of the order status.
+type WaitOptions struct {
+	// Interval is the delay before the second request, 2 seconds by default
+	Interval time.Duration
+	// Backoff multiplies the delay after every request, 1.5 by default, 1 polls with constant interval
+	Backoff float64
+	// MaxInterval limits the delay between requests, 15 seconds by default
+	MaxInterval time.Duration
+	// MaxErrors is the number of transient errors in a row after which polling stops, 5 by default
+	MaxErrors int
+	// Clock is the time source, the real time by default
+	Clock Clock
+}
+
+func (o WaitOptions) withDefaults() WaitOptions {
+	if o.Interval <= 0 {
+		o.Interval = 2 * time.Second
+	}
+	if o.Backoff < 1 {
+		o.Backoff = 1.5
+	}
+	if o.MaxInterval <= 0 {
+		o.MaxInterval = 15 * time.Second
+	}
+	o.MaxInterval = max(o.MaxInterval, o.Interval)
+	if o.MaxErrors <= 0 {
+		o.MaxErrors = 5
+	}
+	if o.Clock == nil {
+		o.Clock = realClock{}
+	}
+
+	return o
+}
+
+// WaitForFinalStatus polls the status of the order until it is final (see schema.OrderStatus.IsFinal),
+// e.g. after the customer returns from formUrl.
+func WaitForFinalStatus(ctx context.Context, orderId string, opts WaitOptions) (*schema.OrderStatusResponse, error) {
+	return getClient().WaitForFinalStatus(ctx, orderId, opts)
+}
+
+// WaitForFinalStatus polls the status of the order until it is final (see schema.OrderStatus.IsFinal),
+// e.g. after the customer returns from formUrl. When ctx is done or polling fails,
+// the last received status is returned with the error.
+func (c Client) WaitForFinalStatus(ctx context.Context, orderId string, opts WaitOptions) (*schema.OrderStatusResponse, error) {
+	var last *schema.OrderStatusResponse
+	for status, err := range c.WatchOrderStatus(ctx, orderId, opts) {
+		if err != nil {
+			return last, err
+		}
+		last = status
+	}
+
+	return last, nil
+}
+
+// WatchOrderStatus polls the status of the order and yields it every time the orderStatus changes,
+// the first one included. It stops after a final status or yields the error that stopped polling.
+func WatchOrderStatus(ctx context.Context, orderId string, opts WaitOptions) iter.Seq2[*schema.OrderStatusResponse, error] {
+	return getClient().WatchOrderStatus(ctx, orderId, opts)
+}
+
+// WatchOrderStatus polls the status of the order and yields it every time the orderStatus changes,
+// the first one included. It stops after a final status or yields the error that stopped polling:
+// ctx error, a permanent gateway error (e.g. acquiring.ErrOrderNotFound) or the last of
+// WaitOptions.MaxErrors transient errors in a row.
+//
+//	for status, err := range orders.WatchOrderStatus(ctx, orderId, orders.WaitOptions{}) {
+//		if err != nil {
+//			return err
+//		}
+//		log.Println("order", orderId, "is", status.OrderStatus)
+//	}
+func (c Client) WatchOrderStatus(ctx context.Context, orderId string, opts WaitOptions) iter.Seq2[*schema.OrderStatusResponse, error] {
+	opts = opts.withDefaults()
+
+	return func(yield func(*schema.OrderStatusResponse, error) bool) {
+		request := OrderStatusRequest{OrderId: orderId}
+		if err := request.Validate(); err != nil {
+			yield(nil, err)
+			return
+		}
+
+		var last *schema.OrderStatusResponse
+		delay, errs := opts.Interval, 0
+		for {
+			status, _, err := c.orderStatus(ctx, request)
+			switch {
+			case ctx.Err() != nil:
+				yield(nil, fmt.Errorf("waiting for final status of order %s: %w", orderId, ctx.Err()))
+				return
+			case err != nil:
+				errs++
+				if errs >= opts.MaxErrors || !transient(err) {
+					yield(nil, err)
+					return
+				}
+			default:
+				errs = 0
+				if last == nil || status.OrderStatus != last.OrderStatus {
+					if !yield(status, nil) {
+						return
+					}
+				}
+				if status.OrderStatus.IsFinal() {
+					return
+				}
+				last = status
+			}
+
+			select {
+			case <-ctx.Done():
+				yield(nil, fmt.Errorf("waiting for final status of order %s: %w", orderId, ctx.Err()))
+				return
+			case <-opts.Clock.After(delay):
+			}
+			delay = min(time.Duration(float64(delay)*opts.Backoff), opts.MaxInterval)
+		}
+	}
+}
+
+// transient reports whether the status request may succeed later: network errors,
+// open circuit and gateway errors that are not permanent.
+func transient(err error) bool {
+	var apiErr *acquiring.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Class() != acquiring.ErrorClassPermanent
+	}
+
+	var netErr net.Error
+
+	return errors.As(err, &netErr) || errors.Is(err, acquiring.ErrCircuitOpen)
+}
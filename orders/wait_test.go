@@ -0,0 +1,135 @@
+package orders
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	acquiring "github.com/helios-ag/sberbank-acquiring-go"
+	"github.com/helios-ag/sberbank-acquiring-go/endpoints"
+	"github.com/helios-ag/sberbank-acquiring-go/schema"
+	server "github.com/helios-ag/sberbank-acquiring-go/testing"
+	. "github.com/onsi/gomega"
+)
+
+// fakeClock fires timers at once and records their delays.
+type fakeClock struct {
+	delays []time.Duration
+	// block makes timers never fire
+	block bool
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.delays = append(c.delays, d)
+	ch := make(chan time.Time, 1)
+	if !c.block {
+		ch <- time.Time{}
+	}
+
+	return ch
+}
+
+func TestWaitForFinalStatus(t *testing.T) {
+	RegisterTestingT(t)
+
+	serve := func(responses ...string) server.Server {
+		newServer := server.NewServer()
+		prepareClient(newServer.URL)
+
+		requests := 0
+		newServer.Mux.HandleFunc(endpoints.GetOrderStatusExtended, func(w http.ResponseWriter, r *http.Request) {
+			response := responses[min(requests, len(responses)-1)]
+			requests++
+			if response == "503" {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			fmt.Fprint(w, response)
+		})
+
+		return newServer
+	}
+	status := func(orderStatus schema.OrderStatus) string {
+		return fmt.Sprintf(`{"errorCode":"0","orderNumber":"1234567890","orderStatus":%d}`, orderStatus)
+	}
+
+	t.Run("Changes are yielded until final status", func(t *testing.T) {
+		newServer := serve(
+			status(schema.OrderStatusRegistered),
+			status(schema.OrderStatusRegistered),
+			status(schema.OrderStatusACSAuth),
+			status(schema.OrderStatusACSAuth),
+			status(schema.OrderStatusACSAuth),
+			status(schema.OrderStatusDeposited),
+		)
+		defer newServer.Teardown()
+
+		clock := &fakeClock{}
+		var changes []schema.OrderStatus
+		for status, err := range WatchOrderStatus(context.Background(), "70906e55", WaitOptions{Interval: time.Second, Backoff: 2, MaxInterval: 5 * time.Second, Clock: clock}) {
+			Expect(err).ToNot(HaveOccurred())
+			changes = append(changes, status.OrderStatus)
+		}
+
+		Expect(changes).To(Equal([]schema.OrderStatus{schema.OrderStatusRegistered, schema.OrderStatusACSAuth, schema.OrderStatusDeposited}))
+		Expect(clock.delays).To(Equal([]time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}))
+	})
+
+	t.Run("Final status is returned", func(t *testing.T) {
+		newServer := serve(status(schema.OrderStatusACSAuth), status(schema.OrderStatusDeclined))
+		defer newServer.Teardown()
+
+		final, err := WaitForFinalStatus(context.Background(), "70906e55", WaitOptions{Clock: &fakeClock{}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(final.OrderStatus).To(Equal(schema.OrderStatusDeclined))
+	})
+
+	t.Run("Transient errors are skipped", func(t *testing.T) {
+		newServer := serve("503", `{"errorCode":"7","errorMessage":"Системная ошибка"}`, "503", status(schema.OrderStatusApproved))
+		defer newServer.Teardown()
+
+		clock := &fakeClock{}
+		final, err := WaitForFinalStatus(context.Background(), "70906e55", WaitOptions{MaxErrors: 4, Clock: clock})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(final.OrderStatus).To(Equal(schema.OrderStatusApproved))
+		Expect(clock.delays).To(HaveLen(3))
+	})
+
+	t.Run("Polling stops after too many transient errors", func(t *testing.T) {
+		newServer := serve(status(schema.OrderStatusRegistered), "503")
+		defer newServer.Teardown()
+
+		last, err := WaitForFinalStatus(context.Background(), "70906e55", WaitOptions{MaxErrors: 2, Clock: &fakeClock{}})
+		Expect(err).To(MatchError(ContainSubstring("503")))
+		Expect(last.OrderStatus).To(Equal(schema.OrderStatusRegistered))
+	})
+
+	t.Run("Permanent errors stop polling", func(t *testing.T) {
+		newServer := serve(`{"errorCode":"6","errorMessage":"Заказ не найден"}`)
+		defer newServer.Teardown()
+
+		clock := &fakeClock{}
+		last, err := WaitForFinalStatus(context.Background(), "70906e55", WaitOptions{Clock: clock})
+		Expect(err).To(MatchError(acquiring.ErrOrderNotFound))
+		Expect(last).To(BeNil())
+		Expect(clock.delays).To(BeEmpty())
+
+		_, err = WaitForFinalStatus(context.Background(), "", WaitOptions{Clock: clock})
+		Expect(err).To(MatchError(ContainSubstring("orderId or orderNumber is required")))
+	})
+
+	t.Run("Context cancellation", func(t *testing.T) {
+		newServer := serve(status(schema.OrderStatusRegistered))
+		defer newServer.Teardown()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(10*time.Millisecond, cancel)
+
+		last, err := WaitForFinalStatus(ctx, "70906e55", WaitOptions{Clock: &fakeClock{block: true}})
+		Expect(err).To(MatchError(context.Canceled))
+		Expect(err).To(MatchError(ContainSubstring("waiting for final status of order 70906e55")))
+		Expect(last.OrderStatus).To(Equal(schema.OrderStatusRegistered))
+	})
+}
@@ -0,0 +1,167 @@
+// Package otelacquiring traces calls to the gateway with OpenTelemetry.
+//
+//	client, err := acquiring.NewClient(cfg, otelacquiring.WithTracing())
+//
+// Spans carry endpoint, order, errorCode, actionCode and HTTP status of a call.
+// Request params are never recorded, so PAN and credentials don't leave the process.
+package otelacquiring
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	acquiring "github.com/helios-ag/sberbank-acquiring-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/helios-ag/sberbank-acquiring-go/otelacquiring"
+
+// Span attributes
+const (
+	EndpointKey    = attribute.Key("sberbank.endpoint")
+	AttemptKey     = attribute.Key("sberbank.attempt")
+	OrderNumberKey = attribute.Key("sberbank.order_number")
+	OrderIdKey     = attribute.Key("sberbank.order_id")
+	ErrorCodeKey   = attribute.Key("sberbank.error_code")
+	ActionCodeKey  = attribute.Key("sberbank.action_code")
+	MethodKey      = attribute.Key("http.request.method")
+	StatusCodeKey  = attribute.Key("http.response.status_code")
+)
+
+type config struct {
+	tracerProvider trace.TracerProvider
+	propagators    propagation.TextMapPropagator
+}
+
+// Option configures tracing.
+type Option func(*config)
+
+// WithTracerProvider sets the provider of the tracer, otel.GetTracerProvider() is used by default.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = provider
+	}
+}
+
+// WithPropagators sets propagators injecting trace context into requests,
+// otel.GetTextMapPropagator() is used by default.
+func WithPropagators(propagators propagation.TextMapPropagator) Option {
+	return func(c *config) {
+		c.propagators = propagators
+	}
+}
+
+// WithTracing configures an acquiring.Client to trace its calls, see Middleware.
+func WithTracing(options ...Option) acquiring.ClientOption {
+	return acquiring.WithMiddleware(Middleware(options...))
+}
+
+// Middleware creates a client span for every call to the gateway. The span is a child
+// of the span from the request context, and its context is propagated in request headers.
+func Middleware(options ...Option) acquiring.Middleware {
+	cfg := config{
+		tracerProvider: otel.GetTracerProvider(),
+		propagators:    otel.GetTextMapPropagator(),
+	}
+	for _, option := range options {
+		option(&cfg)
+	}
+	tracer := cfg.tracerProvider.Tracer(tracerName)
+
+	return func(next acquiring.Handler) acquiring.Handler {
+		return func(req *acquiring.Request) (*acquiring.Response, error) {
+			attrs := []attribute.KeyValue{
+				EndpointKey.String(req.Endpoint),
+				AttemptKey.Int(req.Attempt),
+				MethodKey.String(req.HTTP.Method),
+			}
+			if orderNumber := req.Params.Get("orderNumber"); orderNumber != "" {
+				attrs = append(attrs, OrderNumberKey.String(orderNumber))
+			}
+			if orderId := req.Params.Get("orderId"); orderId != "" {
+				attrs = append(attrs, OrderIdKey.String(orderId))
+			}
+
+			ctx, span := tracer.Start(req.Context(), SpanName(req.Endpoint),
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(attrs...),
+			)
+			defer span.End()
+
+			req.HTTP = req.HTTP.WithContext(ctx)
+			cfg.propagators.Inject(ctx, propagation.HeaderCarrier(req.HTTP.Header))
+
+			resp, err := next(req)
+
+			if resp != nil && resp.HTTP != nil {
+				span.SetAttributes(StatusCodeKey.Int(resp.HTTP.StatusCode))
+				span.SetAttributes(responseAttributes(req, resp.Body)...)
+			}
+
+			var apiErr *acquiring.APIError
+			if errors.As(err, &apiErr) {
+				span.SetAttributes(ErrorCodeKey.Int(apiErr.ErrorCode))
+			}
+			if err != nil {
+				span.SetStatus(codes.Error, err.Error())
+			} else if resp != nil && resp.HTTP != nil && resp.HTTP.StatusCode >= http.StatusBadRequest {
+				span.SetStatus(codes.Error, http.StatusText(resp.HTTP.StatusCode))
+			}
+
+			return resp, err
+		}
+	}
+}
+
+// responseAttributes returns order and actionCode from the response body.
+func responseAttributes(req *acquiring.Request, body []byte) []attribute.KeyValue {
+	var response struct {
+		OrderId     string `json:"orderId"`
+		OrderNumber string `json:"orderNumber"`
+		ActionCode  *int   `json:"actionCode"`
+		Data        struct {
+			OrderId string `json:"orderId"`
+		} `json:"data"`
+	}
+	if json.Unmarshal(body, &response) != nil {
+		return nil
+	}
+
+	var attrs []attribute.KeyValue
+	if response.ActionCode != nil {
+		attrs = append(attrs, ActionCodeKey.Int(*response.ActionCode))
+	}
+	if req.Params.Get("orderId") == "" {
+		orderId := response.OrderId
+		if orderId == "" {
+			orderId = response.Data.OrderId
+		}
+		if orderId != "" {
+			attrs = append(attrs, OrderIdKey.String(orderId))
+		}
+	}
+	if req.Params.Get("orderNumber") == "" && response.OrderNumber != "" {
+		attrs = append(attrs, OrderNumberKey.String(response.OrderNumber))
+	}
+
+	return attrs
+}
+
+// SpanName returns name of the gateway operation, e.g. "register" for endpoints.Register
+// and "applepay/payment" for endpoints.ApplePay.
+func SpanName(endpoint string) string {
+	name := strings.TrimSuffix(endpoint, ".do")
+	for _, prefix := range []string{"/payment/rest/", "/payment/", "/"} {
+		if strings.HasPrefix(name, prefix) {
+			return strings.TrimPrefix(name, prefix)
+		}
+	}
+
+	return name
+}
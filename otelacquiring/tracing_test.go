@@ -0,0 +1,161 @@
+package otelacquiring
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	acquiring "github.com/helios-ag/sberbank-acquiring-go"
+	"github.com/helios-ag/sberbank-acquiring-go/endpoints"
+	server "github.com/helios-ag/sberbank-acquiring-go/testing"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func attributes(span tracetest.SpanStub) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, attr := range span.Attributes {
+		attrs[attr.Key] = attr.Value
+	}
+
+	return attrs
+}
+
+func TestMiddleware(t *testing.T) {
+	RegisterTestingT(t)
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	var traceparent string
+	testServer := server.NewServer()
+	defer testServer.Teardown()
+	testServer.Mux.HandleFunc(endpoints.Register, func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		fmt.Fprint(w, `{"orderId":"70906e55","formUrl":"https://form"}`)
+	})
+	testServer.Mux.HandleFunc(endpoints.GetOrderStatusExtended, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"errorCode":"0","orderNumber":"42","orderStatus":6,"actionCode":-2007,"cardAuthInfo":{"maskedPan":"411111**1111"}}`)
+	})
+	testServer.Mux.HandleFunc(endpoints.Deposit, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"errorCode":"6","errorMessage":"Заказ не найден"}`)
+	})
+	testServer.Mux.HandleFunc(endpoints.ApplePay, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"success":true,"data":{"orderId":"70906e55"}}`)
+	})
+
+	client, err := acquiring.NewClient(
+		acquiring.ClientConfig{UserName: "test-api", Password: "secret-password"},
+		acquiring.WithEndpoint(testServer.URL),
+		WithTracing(WithTracerProvider(provider), WithPropagators(propagation.TraceContext{})),
+	)
+	Expect(err).ToNot(HaveOccurred())
+
+	call := func(ctx context.Context, path string, data map[string]string) error {
+		req, err := client.NewRestRequest(ctx, http.MethodPost, path, data, nil)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = client.Do(req, nil)
+		return err
+	}
+
+	t.Run("Span per operation with propagated context", func(t *testing.T) {
+		exporter.Reset()
+		ctx, parent := provider.Tracer("test").Start(context.Background(), "checkout")
+		err := call(ctx, endpoints.Register, map[string]string{"orderNumber": "42", "amount": "100"})
+		parent.End()
+		Expect(err).ToNot(HaveOccurred())
+
+		spans := exporter.GetSpans()
+		Expect(spans).To(HaveLen(2))
+		span := spans[0]
+		Expect(span.Name).To(Equal("register"))
+		Expect(span.SpanKind).To(Equal(trace.SpanKindClient))
+		Expect(span.Parent.SpanID()).To(Equal(parent.SpanContext().SpanID()))
+		Expect(span.SpanContext.TraceID()).To(Equal(parent.SpanContext().TraceID()))
+		Expect(traceparent).To(ContainSubstring(span.SpanContext.SpanID().String()))
+
+		attrs := attributes(span)
+		Expect(attrs[EndpointKey].AsString()).To(Equal(endpoints.Register))
+		Expect(attrs[OrderNumberKey].AsString()).To(Equal("42"))
+		Expect(attrs[OrderIdKey].AsString()).To(Equal("70906e55"))
+		Expect(attrs[StatusCodeKey].AsInt64()).To(BeEquivalentTo(200))
+		Expect(attrs).ToNot(HaveKey(ErrorCodeKey))
+	})
+
+	t.Run("actionCode is recorded", func(t *testing.T) {
+		exporter.Reset()
+		Expect(call(context.Background(), endpoints.GetOrderStatusExtended, map[string]string{"orderId": "70906e55"})).To(Succeed())
+
+		spans := exporter.GetSpans()
+		Expect(spans).To(HaveLen(1))
+		Expect(spans[0].Name).To(Equal("getOrderStatusExtended"))
+		attrs := attributes(spans[0])
+		Expect(attrs[OrderIdKey].AsString()).To(Equal("70906e55"))
+		Expect(attrs[OrderNumberKey].AsString()).To(Equal("42"))
+		Expect(attrs[ActionCodeKey].AsInt64()).To(BeEquivalentTo(-2007))
+	})
+
+	t.Run("Gateway error", func(t *testing.T) {
+		exporter.Reset()
+		Expect(call(context.Background(), endpoints.Deposit, map[string]string{"orderId": "70906e55"})).To(MatchError(acquiring.ErrOrderNotFound))
+
+		spans := exporter.GetSpans()
+		Expect(spans).To(HaveLen(1))
+		Expect(spans[0].Name).To(Equal("deposit"))
+		Expect(spans[0].Status.Code).To(Equal(codes.Error))
+		Expect(attributes(spans[0])[ErrorCodeKey].AsInt64()).To(BeEquivalentTo(6))
+	})
+
+	t.Run("Mobile payment", func(t *testing.T) {
+		exporter.Reset()
+		req, err := client.NewRequest(context.Background(), http.MethodPost, endpoints.ApplePay, map[string]string{
+			"merchant":     "test",
+			"orderNumber":  "42",
+			"paymentToken": "secret-payment-token",
+		})
+		Expect(err).ToNot(HaveOccurred())
+		_, err = client.Do(req, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		spans := exporter.GetSpans()
+		Expect(spans).To(HaveLen(1))
+		Expect(spans[0].Name).To(Equal("applepay/payment"))
+		Expect(attributes(spans[0])[OrderIdKey].AsString()).To(Equal("70906e55"))
+	})
+
+	t.Run("PAN and credentials are never recorded", func(t *testing.T) {
+		exporter.Reset()
+		_ = call(context.Background(), endpoints.InstantRefund, map[string]string{
+			"pan": "4111111111111111",
+			"cvc": "123",
+		})
+		_ = call(context.Background(), endpoints.GetOrderStatusExtended, nil)
+
+		spans := exporter.GetSpans()
+		Expect(spans).To(HaveLen(2))
+		for _, span := range spans {
+			for _, attr := range span.Attributes {
+				value := attr.Value.Emit()
+				Expect(value).ToNot(ContainSubstring("4111111111111111"))
+				Expect(value).ToNot(ContainSubstring("secret"))
+				Expect(strings.ToLower(string(attr.Key))).ToNot(ContainSubstring("pan"))
+			}
+		}
+	})
+}
+
+func TestSpanName(t *testing.T) {
+	RegisterTestingT(t)
+
+	Expect(SpanName(endpoints.Register)).To(Equal("register"))
+	Expect(SpanName(endpoints.GetOrderStatusExtended)).To(Equal("getOrderStatusExtended"))
+	Expect(SpanName(endpoints.ApplePay)).To(Equal("applepay/payment"))
+	Expect(SpanName(endpoints.ExternalReceipt)).To(Equal("fes-nspk-proxy/externalReceipt"))
+}
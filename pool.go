@@ -0,0 +1,143 @@
+package sberbank_acquiring_go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// ErrProfileNotFound is returned by a ProfileProvider that doesn't know the merchant.
+var ErrProfileNotFound = errors.New("merchant profile not found")
+
+// Profile is the configuration of a merchant client in a ClientPool.
+type Profile struct {
+	Config ClientConfig
+	// Options are applied after the options of the pool, e.g. WithToken or WithClientCertificate
+	Options []ClientOption
+}
+
+// ProfileProvider resolves the profile of a merchant by merchant login or tenant ID.
+// It is called by ClientPool on the first lookup of the key and after Reload.
+type ProfileProvider interface {
+	Profile(ctx context.Context, key string) (Profile, error)
+}
+
+// ProfileProviderFunc is a function used as ProfileProvider.
+type ProfileProviderFunc func(ctx context.Context, key string) (Profile, error)
+
+// Profile calls f(ctx, key).
+func (f ProfileProviderFunc) Profile(ctx context.Context, key string) (Profile, error) {
+	return f(ctx, key)
+}
+
+// Profiles is a static ProfileProvider.
+type Profiles map[string]Profile
+
+// Profile returns the profile of the key or ErrProfileNotFound.
+func (p Profiles) Profile(_ context.Context, key string) (Profile, error) {
+	profile, ok := p[key]
+	if !ok {
+		return Profile{}, fmt.Errorf("%w: %s", ErrProfileNotFound, key)
+	}
+
+	return profile, nil
+}
+
+// ClientPool holds clients of many merchants, e.g. sub-merchants of a marketplace.
+// Clients are created on the first lookup and share the http.Client of the pool,
+// unless a profile configures own transport, proxy or certificate.
+// ClientPool is safe for concurrent use.
+type ClientPool struct {
+	provider   ProfileProvider
+	options    []ClientOption
+	httpClient *http.Client
+
+	mu         sync.RWMutex
+	clients    map[string]*Client
+	generation uint64
+}
+
+// NewClientPool creates a pool of clients configured with options and profiles of the provider.
+// Limits and circuit breakers passed in options are shared by all clients of the pool,
+// per merchant ones are set with Profile.Options.
+func NewClientPool(provider ProfileProvider, options ...ClientOption) (*ClientPool, error) {
+	template := newAPI(&ClientConfig{}, options...)
+	if template.err != nil {
+		return nil, template.err
+	}
+
+	return &ClientPool{
+		provider:   provider,
+		options:    options[:len(options):len(options)],
+		httpClient: template.httpClient,
+		clients:    make(map[string]*Client),
+	}, nil
+}
+
+// Client returns the client of the merchant login or tenant ID.
+func (p *ClientPool) Client(ctx context.Context, key string) (*Client, error) {
+	p.mu.RLock()
+	client, ok := p.clients[key]
+	generation := p.generation
+	p.mu.RUnlock()
+
+	if ok {
+		return client, nil
+	}
+
+	profile, err := p.provider.Profile(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	options := append(p.options, withSharedHTTPClient(p.httpClient))
+	client, err = NewClient(profile.Config, append(options, profile.Options...)...)
+	if err != nil {
+		return nil, fmt.Errorf("invalid profile %s: %w", key, err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if cached, ok := p.clients[key]; ok {
+		return cached, nil
+	}
+	if generation == p.generation {
+		p.clients[key] = client
+	}
+
+	return client, nil
+}
+
+// Reload drops the client of the key, the profile is resolved again on the next lookup.
+// Requests already sent with the client are not affected.
+func (p *ClientPool) Reload(key string) {
+	p.mu.Lock()
+	delete(p.clients, key)
+	p.generation++
+	p.mu.Unlock()
+}
+
+// ReloadAll drops all clients of the pool.
+func (p *ClientPool) ReloadAll() {
+	p.mu.Lock()
+	clear(p.clients)
+	p.generation++
+	p.mu.Unlock()
+}
+
+// CloseIdleConnections closes idle connections of the shared transport.
+func (p *ClientPool) CloseIdleConnections() {
+	p.httpClient.CloseIdleConnections()
+}
+
+// withSharedHTTPClient sets the http.Client prepared by the pool, so that transport options
+// of the pool are not applied again and the transport is shared.
+func withSharedHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *Client) {
+		c.httpClient = httpClient
+		c.transport = transportOptions{}
+	}
+}
@@ -0,0 +1,146 @@
+package sberbank_acquiring_go
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/helios-ag/sberbank-acquiring-go/endpoints"
+	server "github.com/helios-ag/sberbank-acquiring-go/testing"
+	. "github.com/onsi/gomega"
+)
+
+func TestClientPool(t *testing.T) {
+	RegisterTestingT(t)
+
+	testServer := server.NewServer()
+	defer testServer.Teardown()
+
+	testServer.Mux.HandleFunc(endpoints.GetOrderStatusExtended, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		params, _ := url.ParseQuery(string(body))
+		fmt.Fprintf(w, `{"errorCode":"0","orderNumber":"%s"}`, params.Get("userName")+params.Get("token"))
+	})
+
+	merchant := func(client *Client) string {
+		req, err := client.NewRestRequest(context.Background(), http.MethodPost, endpoints.GetOrderStatusExtended, nil, nil)
+		Expect(err).ToNot(HaveOccurred())
+		var response struct {
+			OrderNumber string `json:"orderNumber"`
+		}
+		_, err = client.Do(req, &response)
+		Expect(err).ToNot(HaveOccurred())
+
+		return response.OrderNumber
+	}
+
+	var mu sync.Mutex
+	var resolved atomic.Int32
+	passwords := map[string]string{"shop-1": "secret-1", "shop-2": "secret-2"}
+	provider := ProfileProviderFunc(func(ctx context.Context, key string) (Profile, error) {
+		resolved.Add(1)
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch key {
+		case "shop-1", "shop-2":
+			return Profile{Config: ClientConfig{UserName: key + "-api", Password: passwords[key]}}, nil
+		case "tenant-3":
+			return Profile{Options: []ClientOption{WithToken("token-3")}}, nil
+		case "pinned":
+			return Profile{Config: testConfig, Options: []ClientOption{WithPinnedSPKI("c3BraS1zaGEyNTYtb2YtdGhlLWdhdGV3YXkta2V5ISE=")}}, nil
+		case "invalid":
+			return Profile{}, nil
+		}
+
+		return Profiles{}.Profile(ctx, key)
+	})
+
+	pool, err := NewClientPool(provider, WithEndpoint(testServer.URL), WithUserAgent("marketplace"))
+	Expect(err).ToNot(HaveOccurred())
+
+	t.Run("Clients are resolved per merchant and cached", func(t *testing.T) {
+		shop1, err := pool.Client(context.Background(), "shop-1")
+		Expect(err).ToNot(HaveOccurred())
+		shop2, err := pool.Client(context.Background(), "shop-2")
+		Expect(err).ToNot(HaveOccurred())
+		tenant, err := pool.Client(context.Background(), "tenant-3")
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(merchant(shop1)).To(Equal("shop-1-api"))
+		Expect(merchant(shop2)).To(Equal("shop-2-api"))
+		Expect(merchant(tenant)).To(Equal("token-3"))
+		Expect(shop1.userAgent).To(Equal("marketplace"))
+
+		again, err := pool.Client(context.Background(), "shop-1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(again).To(BeIdenticalTo(shop1))
+		Expect(resolved.Load()).To(BeEquivalentTo(3))
+	})
+
+	t.Run("Transport is shared", func(t *testing.T) {
+		shop1, _ := pool.Client(context.Background(), "shop-1")
+		shop2, _ := pool.Client(context.Background(), "shop-2")
+		pinned, err := pool.Client(context.Background(), "pinned")
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(shop1.httpClient).To(BeIdenticalTo(shop2.httpClient))
+		Expect(pinned.httpClient.Transport).ToNot(BeIdenticalTo(shop1.httpClient.Transport))
+	})
+
+	t.Run("Reload resolves credentials again", func(t *testing.T) {
+		shop1, _ := pool.Client(context.Background(), "shop-1")
+
+		mu.Lock()
+		passwords["shop-1"] = "rotated"
+		mu.Unlock()
+		pool.Reload("shop-1")
+
+		reloaded, err := pool.Client(context.Background(), "shop-1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reloaded).ToNot(BeIdenticalTo(shop1))
+		Expect(reloaded.Config.Password).To(Equal("rotated"))
+
+		shop2, _ := pool.Client(context.Background(), "shop-2")
+		pool.ReloadAll()
+		Expect(pool.Client(context.Background(), "shop-2")).ToNot(BeIdenticalTo(shop2))
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		_, err := pool.Client(context.Background(), "unknown")
+		Expect(err).To(MatchError(ErrProfileNotFound))
+
+		_, err = pool.Client(context.Background(), "invalid")
+		Expect(err).To(MatchError(ContainSubstring("invalid profile invalid")))
+
+		_, err = NewClientPool(provider, WithPinnedSPKI("not a pin"))
+		Expect(err).To(MatchError(ContainSubstring("invalid SPKI pin")))
+	})
+
+	t.Run("Concurrent lookups", func(t *testing.T) {
+		pool.ReloadAll()
+
+		var wg sync.WaitGroup
+		clients := make([]*Client, 16)
+		for i := range clients {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if i%4 == 0 {
+					pool.Reload("shop-2")
+				}
+				clients[i], _ = pool.Client(context.Background(), []string{"shop-1", "shop-2"}[i%2])
+			}()
+		}
+		wg.Wait()
+
+		for _, client := range clients {
+			Expect(client).ToNot(BeNil())
+		}
+	})
+}
@@ -0,0 +1,212 @@
+// Package promacquiring collects Prometheus metrics of calls to the gateway.
+//
+//	collector := promacquiring.NewCollector()
+//	prometheus.MustRegister(collector)
+//	client, err := acquiring.NewClient(cfg, promacquiring.WithMetrics(collector))
+package promacquiring
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	acquiring "github.com/helios-ag/sberbank-acquiring-go"
+	"github.com/helios-ag/sberbank-acquiring-go/endpoints"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Outcomes of a call
+const (
+	OutcomeSuccess      = "success"
+	OutcomeGatewayError = "gateway_error"
+	OutcomeHTTPError    = "http_error"
+	OutcomeNetworkError = "network_error"
+)
+
+// StatusUnknown is the "from" label of the first observed status of an order.
+const StatusUnknown = "unknown"
+
+type config struct {
+	namespace     string
+	buckets       []float64
+	trackedOrders int
+}
+
+// Option configures a Collector.
+type Option func(*config)
+
+// WithNamespace sets namespace of the metrics, "sberbank" by default.
+func WithNamespace(namespace string) Option {
+	return func(c *config) {
+		c.namespace = namespace
+	}
+}
+
+// WithBuckets sets buckets of the latency histogram, prometheus.DefBuckets by default.
+func WithBuckets(buckets []float64) Option {
+	return func(c *config) {
+		c.buckets = buckets
+	}
+}
+
+// WithTrackedOrders limits the number of orders whose last status is remembered
+// to count status transitions, 10000 by default.
+func WithTrackedOrders(n int) Option {
+	return func(c *config) {
+		c.trackedOrders = n
+	}
+}
+
+// Collector is a prometheus.Collector of gateway calls.
+type Collector struct {
+	requests    *prometheus.CounterVec
+	duration    *prometheus.HistogramVec
+	transitions *prometheus.CounterVec
+
+	mu       sync.Mutex
+	statuses map[string]int
+	orders   []string
+	next     int
+}
+
+// NewCollector creates a Collector. It has to be registered in a prometheus.Registerer.
+func NewCollector(options ...Option) *Collector {
+	cfg := config{
+		namespace:     "sberbank",
+		buckets:       prometheus.DefBuckets,
+		trackedOrders: 10000,
+	}
+	for _, option := range options {
+		option(&cfg)
+	}
+
+	return &Collector{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: cfg.namespace,
+			Name:      "requests_total",
+			Help:      "Number of calls to the gateway by endpoint, outcome and errorCode.",
+		}, []string{"endpoint", "outcome", "error_code"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: cfg.namespace,
+			Name:      "request_duration_seconds",
+			Help:      "Latency of calls to the gateway by endpoint and outcome.",
+			Buckets:   cfg.buckets,
+		}, []string{"endpoint", "outcome"}),
+		transitions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: cfg.namespace,
+			Name:      "order_status_transitions_total",
+			Help:      "Number of order status changes observed by GetOrderStatus.",
+		}, []string{"from", "to"}),
+		statuses: make(map[string]int),
+		orders:   make([]string, max(cfg.trackedOrders, 1)),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.requests.Describe(ch)
+	c.duration.Describe(ch)
+	c.transitions.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.requests.Collect(ch)
+	c.duration.Collect(ch)
+	c.transitions.Collect(ch)
+}
+
+// WithMetrics configures an acquiring.Client to report its calls to the collector.
+func WithMetrics(collector *Collector) acquiring.ClientOption {
+	return acquiring.WithMiddleware(collector.Middleware())
+}
+
+// Middleware returns middleware reporting every attempt of every call to the collector.
+func (c *Collector) Middleware() acquiring.Middleware {
+	return func(next acquiring.Handler) acquiring.Handler {
+		return func(req *acquiring.Request) (*acquiring.Response, error) {
+			start := time.Now()
+			resp, err := next(req)
+			elapsed := time.Since(start)
+
+			outcome, errorCode := OutcomeSuccess, 0
+			var apiErr *acquiring.APIError
+			switch {
+			case errors.As(err, &apiErr) && (apiErr.ErrorCode != 0 || apiErr.StatusCode < http.StatusBadRequest):
+				// errors without errorCode in a successful response, e.g. "success": false of mobile payments
+				outcome, errorCode = OutcomeGatewayError, apiErr.ErrorCode
+			case apiErr != nil:
+				outcome = OutcomeHTTPError
+			case err != nil:
+				outcome = OutcomeNetworkError
+			}
+
+			c.requests.WithLabelValues(req.Endpoint, outcome, strconv.Itoa(errorCode)).Inc()
+			c.duration.WithLabelValues(req.Endpoint, outcome).Observe(elapsed.Seconds())
+
+			if err == nil && resp != nil && (req.Endpoint == endpoints.GetOrderStatus || req.Endpoint == endpoints.GetOrderStatusExtended) {
+				c.observeStatus(req, resp.Body)
+			}
+
+			return resp, err
+		}
+	}
+}
+
+// observeStatus counts the transition from the last observed status of the order.
+// Orders are tracked by orderId, taken from the request or from the mdOrder attribute of the response,
+// so an order polled both by orderId and by orderNumber is counted once. Without orderId the order
+// is tracked by merchantLogin and orderNumber, as orderNumber is unique only for a merchant.
+func (c *Collector) observeStatus(req *acquiring.Request, body []byte) {
+	var response struct {
+		OrderNumber string `json:"orderNumber"`
+		OrderStatus *int   `json:"orderStatus"`
+		Attributes  []struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+		} `json:"attributes"`
+	}
+	if json.Unmarshal(body, &response) != nil || response.OrderStatus == nil {
+		return
+	}
+
+	order := req.Params.Get("orderId")
+	for _, attribute := range response.Attributes {
+		if order == "" && attribute.Name == "mdOrder" {
+			order = attribute.Value
+		}
+	}
+	if order != "" {
+		order = "id:" + order
+	} else if number := cmp.Or(response.OrderNumber, req.Params.Get("orderNumber")); number != "" {
+		order = "number:" + req.Params.Get("merchantLogin") + "/" + number
+	} else {
+		return
+	}
+
+	status := *response.OrderStatus
+
+	c.mu.Lock()
+	previous, known := c.statuses[order]
+	if !known {
+		// the oldest tracked order is forgotten
+		if oldest := c.orders[c.next]; oldest != "" {
+			delete(c.statuses, oldest)
+		}
+		c.orders[c.next] = order
+		c.next = (c.next + 1) % len(c.orders)
+	}
+	c.statuses[order] = status
+	c.mu.Unlock()
+
+	switch {
+	case !known:
+		c.transitions.WithLabelValues(StatusUnknown, strconv.Itoa(status)).Inc()
+	case previous != status:
+		c.transitions.WithLabelValues(strconv.Itoa(previous), strconv.Itoa(status)).Inc()
+	}
+}
@@ -0,0 +1,199 @@
+package promacquiring
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	acquiring "github.com/helios-ag/sberbank-acquiring-go"
+	"github.com/helios-ag/sberbank-acquiring-go/endpoints"
+	server "github.com/helios-ag/sberbank-acquiring-go/testing"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCollector(t *testing.T) {
+	RegisterTestingT(t)
+
+	status := 0
+	// orderNumbers are orders of the default merchant by orderId
+	orderNumbers := map[string]string{"order-1": "1001", "order-2": "1002"}
+	order := func(r *http.Request) (orderId, orderNumber string) {
+		orderId, orderNumber = r.FormValue("orderId"), r.FormValue("orderNumber")
+		for id, number := range orderNumbers {
+			if id == orderId || (number == orderNumber && r.FormValue("merchantLogin") == "") {
+				return id, number
+			}
+		}
+		return orderId, orderNumber
+	}
+	testServer := server.NewServer()
+	defer testServer.Teardown()
+	testServer.Mux.HandleFunc(endpoints.Register, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"orderId":"70906e55","formUrl":"https://form"}`)
+	})
+	testServer.Mux.HandleFunc(endpoints.Deposit, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"errorCode":"6","errorMessage":"Заказ не найден"}`)
+	})
+	testServer.Mux.HandleFunc(endpoints.Refund, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	testServer.Mux.HandleFunc(endpoints.GetOrderStatusExtended, func(w http.ResponseWriter, r *http.Request) {
+		orderId, orderNumber := order(r)
+		fmt.Fprintf(w, `{"errorCode":"0","orderNumber":%q,"orderStatus":%d,"attributes":[{"name":"mdOrder","value":%q}]}`, orderNumber, status, orderId)
+	})
+	testServer.Mux.HandleFunc(endpoints.GetOrderStatus, func(w http.ResponseWriter, r *http.Request) {
+		_, orderNumber := order(r)
+		fmt.Fprintf(w, `{"ErrorCode":"0","OrderNumber":%q,"OrderStatus":%d}`, orderNumber, status)
+	})
+	testServer.Mux.HandleFunc(endpoints.ApplePay, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"success":false}`)
+	})
+
+	newClient := func(collector *Collector, options ...acquiring.ClientOption) *acquiring.Client {
+		options = append([]acquiring.ClientOption{acquiring.WithEndpoint(testServer.URL), WithMetrics(collector)}, options...)
+		client, err := acquiring.NewClient(acquiring.ClientConfig{UserName: "test-api", Password: "test"}, options...)
+		Expect(err).ToNot(HaveOccurred())
+		return client
+	}
+	call := func(client *acquiring.Client, path string, data map[string]string) error {
+		req, err := client.NewRestRequest(context.Background(), http.MethodPost, path, data, nil)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = client.Do(req, nil)
+		return err
+	}
+
+	t.Run("Requests are counted by endpoint, outcome and errorCode", func(t *testing.T) {
+		collector := NewCollector()
+		client := newClient(collector)
+
+		Expect(call(client, endpoints.Register, nil)).To(Succeed())
+		Expect(call(client, endpoints.Register, nil)).To(Succeed())
+		Expect(call(client, endpoints.Deposit, nil)).To(HaveOccurred())
+		Expect(call(client, endpoints.Refund, nil)).To(HaveOccurred())
+
+		Expect(promtestutil.ToFloat64(collector.requests.WithLabelValues(endpoints.Register, OutcomeSuccess, "0"))).To(Equal(2.0))
+		Expect(promtestutil.ToFloat64(collector.requests.WithLabelValues(endpoints.Deposit, OutcomeGatewayError, "6"))).To(Equal(1.0))
+		Expect(promtestutil.ToFloat64(collector.requests.WithLabelValues(endpoints.Refund, OutcomeHTTPError, "0"))).To(Equal(1.0))
+		Expect(promtestutil.CollectAndCount(collector, "sberbank_request_duration_seconds")).To(Equal(3))
+	})
+
+	t.Run("Unsuccessful mobile payment is a gateway error", func(t *testing.T) {
+		collector := NewCollector()
+		client := newClient(collector)
+
+		req, err := client.NewRequest(context.Background(), http.MethodPost, endpoints.ApplePay, map[string]string{"orderNumber": "1001"})
+		Expect(err).ToNot(HaveOccurred())
+		_, err = client.Do(req, nil)
+		Expect(err).To(HaveOccurred())
+
+		Expect(promtestutil.ToFloat64(collector.requests.WithLabelValues(endpoints.ApplePay, OutcomeGatewayError, "0"))).To(Equal(1.0))
+		Expect(promtestutil.ToFloat64(collector.requests.WithLabelValues(endpoints.ApplePay, OutcomeHTTPError, "0"))).To(BeZero())
+	})
+
+	t.Run("Network errors", func(t *testing.T) {
+		collector := NewCollector()
+		failing := func(next acquiring.Handler) acquiring.Handler {
+			return func(req *acquiring.Request) (*acquiring.Response, error) {
+				return nil, errors.New("connection refused")
+			}
+		}
+		client := newClient(collector, acquiring.WithMiddleware(failing))
+
+		Expect(call(client, endpoints.Register, nil)).To(HaveOccurred())
+		Expect(promtestutil.ToFloat64(collector.requests.WithLabelValues(endpoints.Register, OutcomeNetworkError, "0"))).To(Equal(1.0))
+	})
+
+	t.Run("Order status transitions", func(t *testing.T) {
+		collector := NewCollector()
+		client := newClient(collector)
+		poll := func(endpoint, orderId string, orderStatus int) {
+			status = orderStatus
+			Expect(call(client, endpoint, map[string]string{"orderId": orderId})).To(Succeed())
+		}
+
+		poll(endpoints.GetOrderStatusExtended, "order-1", 0)
+		poll(endpoints.GetOrderStatusExtended, "order-1", 0)
+		poll(endpoints.GetOrderStatusExtended, "order-1", 6)
+		poll(endpoints.GetOrderStatusExtended, "order-2", 0)
+		poll(endpoints.GetOrderStatus, "order-2", 2)
+
+		Expect(promtestutil.ToFloat64(collector.transitions.WithLabelValues(StatusUnknown, "0"))).To(Equal(2.0))
+		Expect(promtestutil.ToFloat64(collector.transitions.WithLabelValues("0", "6"))).To(Equal(1.0))
+		Expect(promtestutil.ToFloat64(collector.transitions.WithLabelValues("0", "2"))).To(Equal(1.0))
+		Expect(promtestutil.CollectAndCount(collector, "sberbank_order_status_transitions_total")).To(Equal(3))
+	})
+
+	t.Run("Order polled by orderId and by orderNumber is tracked once", func(t *testing.T) {
+		collector := NewCollector()
+		client := newClient(collector)
+
+		status = 0
+		Expect(call(client, endpoints.GetOrderStatusExtended, map[string]string{"orderId": "order-1"})).To(Succeed())
+		Expect(call(client, endpoints.GetOrderStatusExtended, map[string]string{"orderNumber": "1001"})).To(Succeed())
+		status = 2
+		Expect(call(client, endpoints.GetOrderStatusExtended, map[string]string{"orderNumber": "1001"})).To(Succeed())
+		Expect(call(client, endpoints.GetOrderStatus, map[string]string{"orderId": "order-1"})).To(Succeed())
+
+		Expect(promtestutil.ToFloat64(collector.transitions.WithLabelValues(StatusUnknown, "0"))).To(Equal(1.0))
+		Expect(promtestutil.ToFloat64(collector.transitions.WithLabelValues("0", "2"))).To(Equal(1.0))
+		Expect(collector.statuses).To(Equal(map[string]int{"id:order-1": 2}))
+	})
+
+	t.Run("Merchants sharing an orderNumber are tracked separately", func(t *testing.T) {
+		collector := NewCollector()
+		client := newClient(collector)
+
+		status = 0
+		Expect(call(client, endpoints.GetOrderStatus, map[string]string{"orderNumber": "5001", "merchantLogin": "shop-a"})).To(Succeed())
+		status = 2
+		Expect(call(client, endpoints.GetOrderStatus, map[string]string{"orderNumber": "5001", "merchantLogin": "shop-b"})).To(Succeed())
+
+		Expect(promtestutil.ToFloat64(collector.transitions.WithLabelValues(StatusUnknown, "0"))).To(Equal(1.0))
+		Expect(promtestutil.ToFloat64(collector.transitions.WithLabelValues(StatusUnknown, "2"))).To(Equal(1.0))
+		Expect(promtestutil.ToFloat64(collector.transitions.WithLabelValues("0", "2"))).To(BeZero())
+		Expect(collector.statuses).To(HaveLen(2))
+	})
+
+	t.Run("Orders without identifiers are not tracked", func(t *testing.T) {
+		collector := NewCollector(WithTrackedOrders(2))
+		client := newClient(collector)
+
+		status = 0
+		Expect(call(client, endpoints.GetOrderStatus, nil)).To(Succeed())
+		Expect(collector.statuses).To(BeEmpty())
+		Expect(promtestutil.CollectAndCount(collector, "sberbank_order_status_transitions_total")).To(BeZero())
+	})
+
+	t.Run("Tracked orders are limited", func(t *testing.T) {
+		collector := NewCollector(WithTrackedOrders(2))
+		client := newClient(collector)
+		status = 0
+		for _, orderId := range []string{"order-1", "order-2", "order-3"} {
+			Expect(call(client, endpoints.GetOrderStatusExtended, map[string]string{"orderId": orderId})).To(Succeed())
+		}
+
+		Expect(collector.statuses).To(HaveLen(2))
+		Expect(collector.statuses).ToNot(HaveKey("id:order-1"))
+		Expect(collector.statuses).To(HaveKey("id:order-3"))
+	})
+
+	t.Run("Collector is registered", func(t *testing.T) {
+		registry := prometheus.NewRegistry()
+		collector := NewCollector(WithNamespace("acquiring"))
+		Expect(registry.Register(collector)).To(Succeed())
+
+		Expect(call(newClient(collector), endpoints.Register, nil)).To(Succeed())
+
+		expected := `
+# HELP acquiring_requests_total Number of calls to the gateway by endpoint, outcome and errorCode.
+# TYPE acquiring_requests_total counter
+acquiring_requests_total{endpoint="/payment/rest/register.do",error_code="0",outcome="success"} 1
+`
+		Expect(promtestutil.GatherAndCompare(registry, strings.NewReader(expected), "acquiring_requests_total")).To(Succeed())
+	})
+}
@@ -2,9 +2,7 @@ package raw_position_refund
 
 import (
 	"context"
-	"encoding/json"
 	"net/http"
-	"strconv"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	acquiring "github.com/helios-ag/sberbank-acquiring-go"
@@ -17,23 +15,34 @@ type Client struct {
 	API acquiring.API
 }
 
+// NewClient creates a Client that sends requests through api,
+// usually a client created with acquiring.NewClient.
+func NewClient(api acquiring.API) Client {
+	return Client{API: api}
+}
+
 // ProcessRawPositionRefundRequest Request is used to carry data related that passed to acquiring api requests.
 type ProcessRawPositionRefundRequest struct {
-	UserName            string
-	Password            string
-	Language            string
-	OrderId             string
-	Amount              int64
-	PositionId          string
-	AdditionalOfdParams *ofd.AdditionalOfdParams
+	UserName            string                   `form:"userName,omitempty"`
+	Password            string                   `form:"password,omitempty"`
+	Token               string                   `form:"token,omitempty"`
+	Language            string                   `form:"language,omitempty"`
+	OrderId             string                   `form:"orderId"`
+	Amount              int64                    `form:"amount"`
+	PositionId          string                   `form:"positionId"`
+	AdditionalOfdParams *ofd.AdditionalOfdParams `form:"additionalOfdParams,omitempty"`
 }
 
 func (request ProcessRawPositionRefundRequest) Validate() error {
+	if request.Token != "" && (request.UserName != "" || request.Password != "") {
+		return acquiring.ErrMixedCredentials
+	}
+
 	return validation.ValidateStruct(&request,
 		validation.Field(&request.OrderId, validation.Required, validation.Length(1, 30)),
 		validation.Field(&request.Amount, validation.Required, validation.Min(1)),
-		validation.Field(&request.UserName, validation.Required),
-		validation.Field(&request.Password, validation.Required),
+		validation.Field(&request.UserName, validation.When(request.Password != "", validation.Required)),
+		validation.Field(&request.Password, validation.When(request.UserName != "", validation.Required)),
 	)
 }
 
@@ -51,34 +60,10 @@ func (c Client) ProcessRawPositionRefund(ctx context.Context, processRawPosition
 	if err := processRawPositionRefundRequest.Validate(); err != nil {
 		return nil, nil, err
 	}
-	var additionalOfdParams, _ = json.Marshal(processRawPositionRefundRequest.AdditionalOfdParams)
 
-	body := map[string]string{
-		"userName":   processRawPositionRefundRequest.UserName,
-		"password":   processRawPositionRefundRequest.Password,
-		"amount":     strconv.FormatInt(processRawPositionRefundRequest.Amount, 10),
-		"language":   processRawPositionRefundRequest.Language,
-		"orderId":    processRawPositionRefundRequest.OrderId,
-		"positionId": processRawPositionRefundRequest.PositionId,
-	}
-	
-	if processRawPositionRefundRequest.AdditionalOfdParams != nil {
-		body["additionalOfdParams"] = string(additionalOfdParams)
-	}
-
-	var ProcessRawPositionRefundResponse schema.ProcessRawRefundResponse
-	req, err := c.API.NewRestRequest(ctx, http.MethodPost, path, body, nil)
-
-	if err != nil {
-		return nil, nil, err
-	}
-	result, err := c.API.Do(req, &ProcessRawPositionRefundResponse)
-	if err != nil {
-		return nil, result, err
-	}
-	_ = json.NewDecoder(result.Body).Decode(&ProcessRawPositionRefundResponse)
+	op := acquiring.Operation[ProcessRawPositionRefundRequest, schema.ProcessRawRefundResponse]{Method: http.MethodPost, Path: path}
 
-	return &ProcessRawPositionRefundResponse, result, err
+	return acquiring.Call(ctx, c.API, op, processRawPositionRefundRequest)
 }
 
 func getClient() Client {
@@ -2,9 +2,7 @@ package raw_sum_refund
 
 import (
 	"context"
-	"encoding/json"
 	"net/http"
-	"strconv"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	acquiring "github.com/helios-ag/sberbank-acquiring-go"
@@ -17,18 +15,25 @@ type Client struct {
 	API acquiring.API
 }
 
+// NewClient creates a Client that sends requests through api,
+// usually a client created with acquiring.NewClient.
+func NewClient(api acquiring.API) Client {
+	return Client{API: api}
+}
+
 // ProcessRawSumRefundRequest Request is used to carry data related that passed to acquiring api requests.
 type ProcessRawSumRefundRequest struct {
-	UserName            string
-	Password            string
-	Language            string
-	OrderId             string
-	Amount              int64
-	Name                string
-	JSONParams          *JSONParams `json:"jsonParams,omitempty"`
-	AdditionalOfdParams *ofd.AdditionalOfdParams
-	ItemCode            string `json:"itemCode"`
-	TaxType             int    `json:"taxType,omitempty"`
+	UserName            string                   `form:"userName,omitempty"`
+	Password            string                   `form:"password,omitempty"`
+	Token               string                   `form:"token,omitempty"`
+	Language            string                   `form:"language,omitempty"`
+	OrderId             string                   `form:"orderId"`
+	Amount              int64                    `form:"amount"`
+	Name                string                   `form:"name,omitempty"`
+	JSONParams          *JSONParams              `json:"jsonParams,omitempty" form:"jsonParams,omitempty"`
+	AdditionalOfdParams *ofd.AdditionalOfdParams `form:"additionalOfdParams,omitempty"`
+	ItemCode            string                   `json:"itemCode" form:"itemCode,omitempty"`
+	TaxType             int                      `json:"taxType,omitempty" form:"taxType"`
 }
 
 type JSONParams struct {
@@ -37,11 +42,15 @@ type JSONParams struct {
 }
 
 func (request ProcessRawSumRefundRequest) Validate() error {
+	if request.Token != "" && (request.UserName != "" || request.Password != "") {
+		return acquiring.ErrMixedCredentials
+	}
+
 	return validation.ValidateStruct(&request,
 		validation.Field(&request.OrderId, validation.Required, validation.Length(1, 30)),
 		validation.Field(&request.Amount, validation.Required, validation.Min(1)),
-		validation.Field(&request.UserName, validation.Required),
-		validation.Field(&request.Password, validation.Required),
+		validation.Field(&request.UserName, validation.When(request.Password != "", validation.Required)),
+		validation.Field(&request.Password, validation.When(request.UserName != "", validation.Required)),
 	)
 }
 
@@ -59,40 +68,10 @@ func (c Client) ProcessRawSumRefund(ctx context.Context, processRawSumRefundRequ
 	if err := processRawSumRefundRequest.Validate(); err != nil {
 		return nil, nil, err
 	}
-	var jsonParams, _ = json.Marshal(processRawSumRefundRequest.JSONParams)
-	var additionalOfdParams, _ = json.Marshal(processRawSumRefundRequest.AdditionalOfdParams)
 
-	body := map[string]string{
-		"userName": processRawSumRefundRequest.UserName,
-		"password": processRawSumRefundRequest.Password,
-		"amount":   strconv.FormatInt(processRawSumRefundRequest.Amount, 10),
-		"language": processRawSumRefundRequest.Language,
-		"orderId":  processRawSumRefundRequest.OrderId,
-		"name":     processRawSumRefundRequest.Name,
-		"itemCode": processRawSumRefundRequest.ItemCode,
-		"taxType":  strconv.Itoa(processRawSumRefundRequest.TaxType),
-	}
-
-	if processRawSumRefundRequest.JSONParams != nil {
-		body["jsonParams"] = string(jsonParams)
-	}
-	if processRawSumRefundRequest.AdditionalOfdParams != nil {
-		body["additionalOfdParams"] = string(additionalOfdParams)
-	}
-
-	var processRawSumRefundResponse schema.ProcessRawRefundResponse
-	req, err := c.API.NewRestRequest(ctx, http.MethodPost, path, body, nil)
-
-	if err != nil {
-		return nil, nil, err
-	}
-	result, err := c.API.Do(req, &processRawSumRefundResponse)
-	if err != nil {
-		return nil, result, err
-	}
-	_ = json.NewDecoder(result.Body).Decode(&processRawSumRefundResponse)
+	op := acquiring.Operation[ProcessRawSumRefundRequest, schema.ProcessRawRefundResponse]{Method: http.MethodPost, Path: path}
 
-	return &processRawSumRefundResponse, result, err
+	return acquiring.Call(ctx, c.API, op, processRawSumRefundRequest)
 }
 
 func getClient() Client {
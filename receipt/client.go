@@ -2,7 +2,6 @@ package receipt
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/http"
 
@@ -15,12 +14,18 @@ type Client struct {
 	API acquiring.API
 }
 
+// NewClient creates a Client that sends requests through api,
+// usually a client created with acquiring.NewClient.
+func NewClient(api acquiring.API) Client {
+	return Client{API: api}
+}
+
 // StatusRequest ReceiptStatusRequest is used for building GetReceipt request
 type StatusRequest struct {
-	OrderId     string
-	OrderNumber string
-	UUID        string
-	JsonParams  map[string]string
+	OrderId     string            `form:"orderId,omitempty"`
+	OrderNumber string            `form:"orderNumber,omitempty"`
+	UUID        string            `form:"uuid,omitempty"`
+	JsonParams  map[string]string `form:"jsonParams,omitempty"`
 }
 
 // GetReceiptStatus request
@@ -38,25 +43,9 @@ func (c Client) GetReceiptStatus(ctx context.Context, receiptStatusRequest Statu
 		return nil, nil, err
 	}
 
-	body := make(map[string]string)
-	body["orderId"] = receiptStatusRequest.OrderId
-	body["orderNumber"] = receiptStatusRequest.OrderNumber
-	body["uuid"] = receiptStatusRequest.UUID
-
-	var response schema.ReceiptStatus
-
-	req, err := c.API.NewRestRequest(ctx, http.MethodGet, path, body, receiptStatusRequest.JsonParams)
-
-	if err != nil {
-		return nil, nil, err
-	}
-	result, err := c.API.Do(req, &response)
-	if err != nil {
-		return nil, result, err
-	}
-	_ = json.NewDecoder(result.Body).Decode(&response)
+	op := acquiring.Operation[StatusRequest, schema.ReceiptStatus]{Method: http.MethodGet, Path: path}
 
-	return &response, result, err
+	return acquiring.Call(ctx, c.API, op, receiptStatusRequest)
 }
 
 func validateReceiptStatusRequest(receiptStatusRequest StatusRequest) error {
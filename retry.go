@@ -0,0 +1,217 @@
+package sberbank_acquiring_go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// ErrOperationApplied is returned when a retried request failed, but reconciliation
+// found that the gateway has performed the operation, e.g. the order is deposited.
+var ErrOperationApplied = errors.New("operation is already applied by the gateway")
+
+// ReconcileFunc checks whether the gateway has performed the request sent to endpoint
+// with params, e.g. whether the order is deposited. See orders.Reconcile.
+type ReconcileFunc func(ctx context.Context, api API, endpoint string, params url.Values) (applied bool, err error)
+
+// RetryPolicy describes how failed requests are repeated.
+//
+// Only idempotent endpoints (order status, bindings, receipt status) are retried by default.
+// Endpoints that move money, e.g. endpoints.Deposit, are retried when listed in UnsafeEndpoints
+// and Reconcile is set: the outcome of the failed request is checked before the next attempt.
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts including the first one
+	MaxAttempts int
+	// BaseDelay is the delay before the second attempt, it doubles with every next attempt
+	BaseDelay time.Duration
+	// MaxDelay limits the delay between attempts, including the one requested with Retry-After
+	MaxDelay time.Duration
+	// Jitter is the share of delay (0..1) that is randomized
+	Jitter float64
+	// UnsafeEndpoints are non-idempotent endpoints allowed to be retried
+	UnsafeEndpoints []string
+	// Reconcile is called before an unsafe request is repeated
+	Reconcile ReconcileFunc
+}
+
+// DefaultRetryPolicy returns policy with 3 attempts and exponential backoff starting from 200ms.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   200 * time.Millisecond,
+		MaxDelay:    5 * time.Second,
+		Jitter:      0.5,
+	}
+}
+
+// WithRetryPolicy configures a Client to repeat failed requests according to the policy.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(c *Client) {
+		c.retry = policy
+	}
+}
+
+// retryMode tells how requests to the endpoint are retried.
+type retryMode int
+
+const (
+	retryNever retryMode = iota
+	retrySafe
+	retryReconciled
+)
+
+func (p RetryPolicy) mode(endpoint string) retryMode {
+	if p.MaxAttempts <= 1 {
+		return retryNever
+	}
+	if readOnlyEndpoints[endpoint] {
+		return retrySafe
+	}
+	if p.Reconcile == nil {
+		return retryNever
+	}
+	for _, unsafe := range p.UnsafeEndpoints {
+		if unsafe == endpoint {
+			return retryReconciled
+		}
+	}
+
+	return retryNever
+}
+
+// maxDuration is the longest time.Duration.
+const maxDuration = time.Duration(math.MaxInt64)
+
+// backoff returns delay before the attempt following the given one.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	shift := min(max(attempt-1, 0), 62)
+	delay := p.BaseDelay
+	if delay > maxDuration>>shift {
+		// doubling would overflow
+		delay = maxDuration
+	} else {
+		delay <<= shift
+	}
+	if delay < 0 {
+		delay = 0
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if p.Jitter > 0 {
+		delay -= time.Duration(rand.Float64() * p.Jitter * float64(delay))
+	}
+
+	return delay
+}
+
+// delay returns delay before the attempt following the given one. Retry-After of the response
+// is honoured up to MaxDelay, waiting past the deadline of the request context is refused by Client.wait.
+func (p RetryPolicy) delay(attempt int, resp *http.Response) time.Duration {
+	after := retryAfter(resp)
+	if p.MaxDelay > 0 {
+		after = min(after, p.MaxDelay)
+	}
+
+	return max(p.backoff(attempt), after)
+}
+
+// Do perform an HTTP request against the API.
+// Errors reported by the gateway are returned as *APIError, even when the status code is 200.
+// Failed requests are repeated according to the RetryPolicy of the client.
+func (c *Client) Do(r *http.Request, v interface{}) (*http.Response, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	if c.isDryRun(r) {
+		return nil, &DryRunError{Request: prepare(r)}
+	}
+
+	endpoint := endpointOf(r)
+	mode := c.retry.mode(endpoint)
+	params := requestParams(r)
+
+	for attempt := 1; ; attempt++ {
+		resp, err := c.do(r, params, attempt, v)
+		if err == nil || mode == retryNever || attempt >= c.retry.MaxAttempts || r.Context().Err() != nil || errors.Is(err, ErrCircuitOpen) {
+			return resp, err
+		}
+
+		var apiErr *APIError
+		class := ErrorClassNeedsReconciliation
+		if errors.As(err, &apiErr) {
+			class = apiErr.Class()
+		}
+		if class == ErrorClassPermanent {
+			return resp, err
+		}
+
+		if mode == retryReconciled && class == ErrorClassNeedsReconciliation {
+			applied, reconcileErr := c.retry.Reconcile(r.Context(), c, endpoint, params)
+			if reconcileErr != nil {
+				return resp, errors.Join(err, fmt.Errorf("reconciliation failed: %w", reconcileErr))
+			}
+			if applied {
+				return resp, fmt.Errorf("%w: %w", ErrOperationApplied, err)
+			}
+		}
+
+		if !c.wait(r.Context(), c.retry.delay(attempt, resp)) {
+			return resp, err
+		}
+
+		if r.GetBody != nil {
+			body, bodyErr := r.GetBody()
+			if bodyErr != nil {
+				return resp, err
+			}
+			r.Body = body
+		}
+	}
+}
+
+// wait sleeps for delay. It returns false if ctx is done or expires earlier.
+func (c *Client) wait(ctx context.Context, delay time.Duration) bool {
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+		return false
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// retryAfter returns delay requested by Retry-After header of the response.
+func retryAfter(resp *http.Response) time.Duration {
+	if resp == nil {
+		return 0
+	}
+
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		if seconds > int(maxDuration/time.Second) {
+			return maxDuration
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0)
+	}
+
+	return 0
+}
@@ -0,0 +1,293 @@
+package sberbank_acquiring_go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/helios-ag/sberbank-acquiring-go/endpoints"
+	server "github.com/helios-ag/sberbank-acquiring-go/testing"
+	. "github.com/onsi/gomega"
+)
+
+type failingTransport struct {
+	failures int32
+	calls    int32
+}
+
+func (f *failingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if atomic.AddInt32(&f.calls, 1) <= f.failures {
+		return nil, errors.New("connection reset by peer")
+	}
+
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestRetryPolicy(t *testing.T) {
+	RegisterTestingT(t)
+
+	policy := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond}
+
+	// serve responds with statuses one by one, the last status is repeated
+	serve := func(path string, statuses ...int) (server.Server, *int32) {
+		var attempts int32
+		testServer := server.NewServer()
+		testServer.Mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			attempt := int(atomic.AddInt32(&attempts, 1))
+			status := statuses[min(attempt, len(statuses))-1]
+
+			Expect(r.ParseForm()).To(Succeed())
+			Expect(r.Form.Get("orderId")).To(Equal("42"))
+
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(status)
+			if status == http.StatusOK {
+				fmt.Fprint(w, `{"errorCode":"0","errorMessage":"Успешно"}`)
+			}
+		})
+
+		return testServer, &attempts
+	}
+
+	do := func(client *Client, ctx context.Context, path string) error {
+		req, err := client.NewRestRequest(ctx, http.MethodPost, path, map[string]string{"orderId": "42"}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = client.Do(req, nil)
+
+		return err
+	}
+
+	t.Run("Idempotent request is retried", func(t *testing.T) {
+		testServer, attempts := serve(endpoints.GetOrderStatusExtended, http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK)
+		defer testServer.Teardown()
+
+		client, err := NewClient(ClientConfig{UserName: "test-api", Password: "test"}, WithEndpoint(testServer.URL), WithRetryPolicy(policy))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(do(client, context.Background(), endpoints.GetOrderStatusExtended)).To(Succeed())
+		Expect(atomic.LoadInt32(attempts)).To(BeEquivalentTo(3))
+	})
+
+	t.Run("Attempts are limited", func(t *testing.T) {
+		testServer, attempts := serve(endpoints.GetBindings, http.StatusServiceUnavailable)
+		defer testServer.Teardown()
+
+		client, _ := NewClient(ClientConfig{UserName: "test-api", Password: "test"}, WithEndpoint(testServer.URL), WithRetryPolicy(policy))
+
+		Expect(do(client, context.Background(), endpoints.GetBindings)).To(MatchError(ErrSystemError))
+		Expect(atomic.LoadInt32(attempts)).To(BeEquivalentTo(3))
+	})
+
+	t.Run("Transport error is retried", func(t *testing.T) {
+		testServer, attempts := serve(endpoints.GetReceiptStatus, http.StatusOK)
+		defer testServer.Teardown()
+
+		transport := &failingTransport{failures: 1}
+		client, _ := NewClient(ClientConfig{UserName: "test-api", Password: "test"},
+			WithEndpoint(testServer.URL), WithHTTPClient(&http.Client{Transport: transport}), WithRetryPolicy(policy))
+
+		Expect(do(client, context.Background(), endpoints.GetReceiptStatus)).To(Succeed())
+		Expect(atomic.LoadInt32(&transport.calls)).To(BeEquivalentTo(2))
+		Expect(atomic.LoadInt32(attempts)).To(BeEquivalentTo(1))
+	})
+
+	t.Run("Permanent error is not retried", func(t *testing.T) {
+		var attempts int32
+		testServer := server.NewServer()
+		defer testServer.Teardown()
+		testServer.Mux.HandleFunc(endpoints.GetOrderStatusExtended, func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&attempts, 1)
+			fmt.Fprint(w, `{"errorCode":"6","errorMessage":"Заказ не найден"}`)
+		})
+
+		client, _ := NewClient(ClientConfig{UserName: "test-api", Password: "test"}, WithEndpoint(testServer.URL), WithRetryPolicy(policy))
+
+		Expect(do(client, context.Background(), endpoints.GetOrderStatusExtended)).To(MatchError(ErrOrderNotFound))
+		Expect(atomic.LoadInt32(&attempts)).To(BeEquivalentTo(1))
+	})
+
+	t.Run("Unsafe request is not retried by default", func(t *testing.T) {
+		testServer, attempts := serve(endpoints.Deposit, http.StatusBadGateway, http.StatusOK)
+		defer testServer.Teardown()
+
+		client, _ := NewClient(ClientConfig{UserName: "test-api", Password: "test"}, WithEndpoint(testServer.URL), WithRetryPolicy(policy))
+
+		Expect(do(client, context.Background(), endpoints.Deposit)).To(MatchError(ErrSystemError))
+		Expect(atomic.LoadInt32(attempts)).To(BeEquivalentTo(1))
+	})
+
+	t.Run("Unsafe request is not retried without reconciliation", func(t *testing.T) {
+		testServer, attempts := serve(endpoints.Deposit, http.StatusBadGateway, http.StatusOK)
+		defer testServer.Teardown()
+
+		unsafe := policy
+		unsafe.UnsafeEndpoints = []string{endpoints.Deposit}
+		client, _ := NewClient(ClientConfig{UserName: "test-api", Password: "test"}, WithEndpoint(testServer.URL), WithRetryPolicy(unsafe))
+
+		Expect(do(client, context.Background(), endpoints.Deposit)).To(HaveOccurred())
+		Expect(atomic.LoadInt32(attempts)).To(BeEquivalentTo(1))
+	})
+
+	t.Run("Unsafe request is reconciled before retry", func(t *testing.T) {
+		testServer, attempts := serve(endpoints.Deposit, http.StatusBadGateway, http.StatusOK)
+		defer testServer.Teardown()
+
+		var reconciled []url.Values
+		unsafe := policy
+		unsafe.UnsafeEndpoints = []string{endpoints.Deposit}
+		unsafe.Reconcile = func(ctx context.Context, api API, endpoint string, params url.Values) (bool, error) {
+			Expect(endpoint).To(Equal(endpoints.Deposit))
+			reconciled = append(reconciled, params)
+			return false, nil
+		}
+		client, _ := NewClient(ClientConfig{UserName: "test-api", Password: "test"}, WithEndpoint(testServer.URL), WithRetryPolicy(unsafe))
+
+		Expect(do(client, context.Background(), endpoints.Deposit)).To(Succeed())
+		Expect(atomic.LoadInt32(attempts)).To(BeEquivalentTo(2))
+		Expect(reconciled).To(HaveLen(1))
+		Expect(reconciled[0].Get("orderId")).To(Equal("42"))
+	})
+
+	t.Run("Applied unsafe request is not repeated", func(t *testing.T) {
+		testServer, attempts := serve(endpoints.Deposit, http.StatusBadGateway, http.StatusOK)
+		defer testServer.Teardown()
+
+		unsafe := policy
+		unsafe.UnsafeEndpoints = []string{endpoints.Deposit}
+		unsafe.Reconcile = func(ctx context.Context, api API, endpoint string, params url.Values) (bool, error) {
+			return true, nil
+		}
+		client, _ := NewClient(ClientConfig{UserName: "test-api", Password: "test"}, WithEndpoint(testServer.URL), WithRetryPolicy(unsafe))
+
+		err := do(client, context.Background(), endpoints.Deposit)
+		Expect(err).To(MatchError(ErrOperationApplied))
+		Expect(err).To(MatchError(ErrSystemError))
+		Expect(atomic.LoadInt32(attempts)).To(BeEquivalentTo(1))
+	})
+
+	t.Run("Failed reconciliation stops retries", func(t *testing.T) {
+		testServer, attempts := serve(endpoints.Refund, http.StatusBadGateway, http.StatusOK)
+		defer testServer.Teardown()
+
+		unsafe := policy
+		unsafe.UnsafeEndpoints = []string{endpoints.Refund}
+		unsafe.Reconcile = func(ctx context.Context, api API, endpoint string, params url.Values) (bool, error) {
+			return false, errors.New("status is unavailable")
+		}
+		client, _ := NewClient(ClientConfig{UserName: "test-api", Password: "test"}, WithEndpoint(testServer.URL), WithRetryPolicy(unsafe))
+
+		err := do(client, context.Background(), endpoints.Refund)
+		Expect(err).To(MatchError(ContainSubstring("reconciliation failed: status is unavailable")))
+		Expect(atomic.LoadInt32(attempts)).To(BeEquivalentTo(1))
+	})
+
+	t.Run("Context cancels waiting", func(t *testing.T) {
+		testServer, attempts := serve(endpoints.GetOrderStatusExtended, http.StatusServiceUnavailable)
+		defer testServer.Teardown()
+
+		slow := policy
+		slow.BaseDelay = time.Hour
+		slow.MaxDelay = time.Hour
+		client, _ := NewClient(ClientConfig{UserName: "test-api", Password: "test"}, WithEndpoint(testServer.URL), WithRetryPolicy(slow))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(20*time.Millisecond, cancel)
+
+		start := time.Now()
+		Expect(do(client, ctx, endpoints.GetOrderStatusExtended)).To(MatchError(ErrSystemError))
+		Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+		Expect(atomic.LoadInt32(attempts)).To(BeEquivalentTo(1))
+	})
+
+	t.Run("Backoff", func(t *testing.T) {
+		backoff := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}
+		Expect(backoff.backoff(1)).To(Equal(100 * time.Millisecond))
+		Expect(backoff.backoff(2)).To(Equal(200 * time.Millisecond))
+		Expect(backoff.backoff(3)).To(Equal(300 * time.Millisecond))
+		Expect(backoff.backoff(70)).To(Equal(300 * time.Millisecond))
+
+		backoff.Jitter = 0.5
+		for i := 0; i < 100; i++ {
+			Expect(backoff.backoff(1)).To(BeNumerically("~", 75*time.Millisecond, 25*time.Millisecond))
+		}
+	})
+
+	t.Run("Backoff doesn't overflow", func(t *testing.T) {
+		capped := RetryPolicy{BaseDelay: 3 * time.Second, MaxDelay: time.Minute}
+		uncapped := RetryPolicy{BaseDelay: 3 * time.Second}
+
+		previous := time.Duration(0)
+		for attempt := 1; attempt <= 200; attempt++ {
+			Expect(capped.backoff(attempt)).To(BeNumerically(">", 0), "attempt %d", attempt)
+			Expect(capped.backoff(attempt)).To(BeNumerically("<=", time.Minute), "attempt %d", attempt)
+
+			delay := uncapped.backoff(attempt)
+			Expect(delay).To(BeNumerically(">=", previous), "attempt %d", attempt)
+			previous = delay
+		}
+		Expect(capped.backoff(40)).To(Equal(time.Minute))
+		Expect(uncapped.backoff(200)).To(Equal(time.Duration(math.MaxInt64)))
+	})
+
+	t.Run("Retry-After is limited by MaxDelay", func(t *testing.T) {
+		var attempts int32
+		testServer := server.NewServer()
+		defer testServer.Teardown()
+		testServer.Mux.HandleFunc(endpoints.GetOrderStatusExtended, func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&attempts, 1)
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusServiceUnavailable)
+		})
+		client, _ := NewClient(ClientConfig{UserName: "test-api", Password: "test"}, WithEndpoint(testServer.URL), WithRetryPolicy(policy))
+
+		start := time.Now()
+		Expect(do(client, context.Background(), endpoints.GetOrderStatusExtended)).To(MatchError(ErrSystemError))
+		Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+		Expect(atomic.LoadInt32(&attempts)).To(BeEquivalentTo(3))
+
+		response := &http.Response{Header: http.Header{"Retry-After": []string{"3600"}}}
+		Expect(policy.delay(1, response)).To(Equal(policy.MaxDelay))
+		Expect(RetryPolicy{}.delay(1, response)).To(Equal(time.Hour))
+	})
+
+	t.Run("Retry-After past the context deadline stops retries", func(t *testing.T) {
+		var attempts int32
+		testServer := server.NewServer()
+		defer testServer.Teardown()
+		testServer.Mux.HandleFunc(endpoints.GetOrderStatusExtended, func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&attempts, 1)
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusServiceUnavailable)
+		})
+		unbounded := policy
+		unbounded.MaxDelay = 0
+		client, _ := NewClient(ClientConfig{UserName: "test-api", Password: "test"}, WithEndpoint(testServer.URL), WithRetryPolicy(unbounded))
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		start := time.Now()
+		Expect(do(client, ctx, endpoints.GetOrderStatusExtended)).To(MatchError(ErrSystemError))
+		Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+		Expect(atomic.LoadInt32(&attempts)).To(BeEquivalentTo(1))
+	})
+
+	t.Run("Retry-After", func(t *testing.T) {
+		response := func(value string) *http.Response {
+			return &http.Response{Header: http.Header{"Retry-After": []string{value}}}
+		}
+
+		Expect(retryAfter(nil)).To(BeZero())
+		Expect(retryAfter(response("2"))).To(Equal(2 * time.Second))
+		Expect(retryAfter(response("99999999999999999"))).To(Equal(time.Duration(math.MaxInt64)))
+		Expect(retryAfter(response("soon"))).To(BeZero())
+		Expect(retryAfter(response(time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)))).To(BeNumerically("~", time.Minute, 2*time.Second))
+		Expect(retryAfter(response(time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat)))).To(BeZero())
+	})
+}
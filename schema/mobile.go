@@ -32,13 +32,13 @@ type ApplePaymentResponse struct {
 			Name  string `json:"name"`
 			Value string `json:"value"`
 		} `json:"merchantOrderParams"`
-		OrderNumber       string `json:"orderNumber"`
-		OrderStatus       int    `json:"orderStatus"`
+		OrderNumber       string      `json:"orderNumber"`
+		OrderStatus       OrderStatus `json:"orderStatus"`
 		PaymentAmountInfo struct {
-			ApprovedAmount  int    `json:"approvedAmount"`
-			DepositedAmount int    `json:"depositedAmount"`
-			PaymentState    string `json:"paymentState"`
-			RefundedAmount  int    `json:"refundedAmount"`
+			ApprovedAmount  int          `json:"approvedAmount"`
+			DepositedAmount int          `json:"depositedAmount"`
+			PaymentState    PaymentState `json:"paymentState"`
+			RefundedAmount  int          `json:"refundedAmount"`
 		} `json:"paymentAmountInfo"`
 		TerminalID string `json:"terminalId"`
 	} `json:"orderStatus"`
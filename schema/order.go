@@ -14,19 +14,38 @@ type OrderResponse struct {
 	ErrorMessage string `json:"errorMessage,omitempty"`
 }
 
-// OrderStatusResponse is response from GetOrderStatus request
+// OrderStatusBasicResponse is response from getOrderStatus.do request
+type OrderStatusBasicResponse struct {
+	OrderNumber    string      `json:"OrderNumber"`
+	OrderStatus    OrderStatus `json:"OrderStatus"`
+	ErrorCode      int         `json:"ErrorCode,string,omitempty"`
+	ErrorMessage   string      `json:"ErrorMessage,omitempty"`
+	Pan            string      `json:"Pan,omitempty"`
+	Expiration     int         `json:"expiration,omitempty"`
+	CardholderName string      `json:"cardholderName,omitempty"`
+	Amount         int         `json:"Amount"`
+	DepositAmount  int         `json:"depositAmount,omitempty"`
+	Currency       int         `json:"currency,string,omitempty"`
+	ApprovalCode   string      `json:"approvalCode,omitempty"`
+	AuthCode       int         `json:"authCode,omitempty"`
+	Ip             string      `json:"Ip,omitempty"`
+	ClientId       string      `json:"clientId,omitempty"`
+	BindingId      string      `json:"bindingId,omitempty"`
+}
+
+// OrderStatusResponse is response from getOrderStatusExtended.do request
 type OrderStatusResponse struct {
-	OrderNumber           string `json:"orderNumber"`
-	OrderStatus           int    `json:"orderStatus,omitempty"`
-	ActionCode            int    `json:"actionCode"`
-	ActionCodeDescription string `json:"actionCodeDescription"`
-	ErrorCode             int    `json:"errorCode,string,omitempty"`
-	ErrorMessage          string `json:"errorMessage,omitempty"`
-	Amount                int    `json:"amount"`
-	Currency              int    `json:"currency,omitempty"`
-	Date                  string `json:"date"`
-	OrderDescription      string `json:"orderDescription,omitempty"`
-	Ip                    string `json:"ip"`
+	OrderNumber           string      `json:"orderNumber"`
+	OrderStatus           OrderStatus `json:"orderStatus,omitempty"`
+	ActionCode            int         `json:"actionCode"`
+	ActionCodeDescription string      `json:"actionCodeDescription"`
+	ErrorCode             int         `json:"errorCode,string,omitempty"`
+	ErrorMessage          string      `json:"errorMessage,omitempty"`
+	Amount                int         `json:"amount"`
+	Currency              int         `json:"currency,omitempty"`
+	Date                  string      `json:"date"`
+	OrderDescription      string      `json:"orderDescription,omitempty"`
+	Ip                    string      `json:"ip"`
 	MerchantOrderParams   []struct {
 		Name  string `json:"name"`
 		Value string `json:"value"`
@@ -58,10 +77,90 @@ type OrderStatusResponse struct {
 	} `json:"bankName,omitempty"`
 	TerminalId        string `json:"terminalId"`
 	PaymentAmountInfo struct {
-		ApprovedAmount  int    `json:"approvedAmount,omitempty"`
-		DepositedAmount int    `json:"depositedAmount,omitempty"`
-		RefundedAmount  int    `json:"refundedAmount,omitempty"`
-		PaymentState    string `json:"paymentState"`
-		FeeAmount       int    `json:"feeAmount"`
+		ApprovedAmount  int          `json:"approvedAmount,omitempty"`
+		DepositedAmount int          `json:"depositedAmount,omitempty"`
+		RefundedAmount  int          `json:"refundedAmount,omitempty"`
+		PaymentState    PaymentState `json:"paymentState"`
+		FeeAmount       int          `json:"feeAmount"`
 	} `json:"paymentAmountInfo,omitempty"`
 }
+
+// Attribute returns the value of the order attribute, e.g. "mdOrder" with the orderId
+func (r OrderStatusResponse) Attribute(name string) string {
+	for _, attribute := range r.Attributes {
+		if attribute.Name == name {
+			return attribute.Value
+		}
+	}
+
+	return ""
+}
+
+// MerchantParams returns merchantOrderParams of the order, e.g. added by addParams.do request
+func (r OrderStatusResponse) MerchantParams() map[string]string {
+	params := make(map[string]string, len(r.MerchantOrderParams))
+	for _, param := range r.MerchantOrderParams {
+		params[param.Name] = param.Value
+	}
+
+	return params
+}
+
+// OrderSummary is an order listed by getLastOrdersForMerchants.do request
+type OrderSummary struct {
+	OrderNumber           string      `json:"orderNumber"`
+	OrderStatus           OrderStatus `json:"orderStatus"`
+	ActionCode            int         `json:"actionCode"`
+	ActionCodeDescription string      `json:"actionCodeDescription"`
+	Amount                int         `json:"amount"`
+	Currency              int         `json:"currency,string,omitempty"`
+	// Date is the registration time of the order in milliseconds since the Unix epoch
+	Date                int64  `json:"date"`
+	OrderDescription    string `json:"orderDescription,omitempty"`
+	Ip                  string `json:"ip,omitempty"`
+	MerchantOrderParams []struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	} `json:"merchantOrderParams"`
+	Attributes []struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	} `json:"attributes"`
+	CardAuthInfo struct {
+		MaskedPan      string `json:"maskedPan,omitempty"`
+		Expiration     string `json:"expiration,omitempty"`
+		CardholderName string `json:"cardholderName,omitempty"`
+		ApprovalCode   string `json:"approvalCode,omitempty"`
+		PaymentSystem  string `json:"paymentSystem,omitempty"`
+	} `json:"cardAuthInfo"`
+	AuthDateTime      int64  `json:"authDateTime,omitempty"`
+	AuthRefNum        string `json:"authRefNum,omitempty"`
+	TerminalId        string `json:"terminalId,omitempty"`
+	PaymentAmountInfo struct {
+		ApprovedAmount  int          `json:"approvedAmount,omitempty"`
+		DepositedAmount int          `json:"depositedAmount,omitempty"`
+		RefundedAmount  int          `json:"refundedAmount,omitempty"`
+		PaymentState    PaymentState `json:"paymentState"`
+	} `json:"paymentAmountInfo"`
+}
+
+// OrderId returns the orderId of the order, it is passed in the "mdOrder" attribute
+func (s OrderSummary) OrderId() string {
+	for _, attribute := range s.Attributes {
+		if attribute.Name == "mdOrder" {
+			return attribute.Value
+		}
+	}
+
+	return ""
+}
+
+// LastOrdersResponse is response from getLastOrdersForMerchants.do request
+type LastOrdersResponse struct {
+	ErrorCode     int            `json:"errorCode,string,omitempty"`
+	ErrorMessage  string         `json:"errorMessage,omitempty"`
+	OrderStatuses []OrderSummary `json:"orderStatuses"`
+	TotalCount    int            `json:"totalCount"`
+	Page          int            `json:"page"`
+	PageSize      int            `json:"pageSize"`
+}
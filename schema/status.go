@@ -0,0 +1,174 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+)
+
+// ErrInvalidTransition is returned by ValidateTransition for status changes the gateway never makes.
+var ErrInvalidTransition = errors.New("invalid order status transition")
+
+// OrderStatus is the orderStatus of an order
+// see https://securepayments.sberbank.ru/wiki/doku.php/integration:api:rest:requests:getorderstatusextended
+type OrderStatus int
+
+const (
+	// OrderStatusRegistered is a registered order that is not paid yet
+	OrderStatusRegistered OrderStatus = 0
+	// OrderStatusApproved is a two-phase payment with the amount held on the card
+	OrderStatusApproved OrderStatus = 1
+	// OrderStatusDeposited is a fully authorized payment
+	OrderStatusDeposited OrderStatus = 2
+	// OrderStatusReversed is a cancelled authorization
+	OrderStatusReversed OrderStatus = 3
+	// OrderStatusRefunded is a payment with the amount refunded in full or in part
+	OrderStatusRefunded OrderStatus = 4
+	// OrderStatusACSAuth is an order waiting for 3-D Secure authorization of the issuer
+	OrderStatusACSAuth OrderStatus = 5
+	// OrderStatusDeclined is a declined or expired payment
+	OrderStatusDeclined OrderStatus = 6
+)
+
+var orderStatusNames = map[OrderStatus]string{
+	OrderStatusRegistered: "registered",
+	OrderStatusApproved:   "approved",
+	OrderStatusDeposited:  "deposited",
+	OrderStatusReversed:   "reversed",
+	OrderStatusRefunded:   "refunded",
+	OrderStatusACSAuth:    "acs_auth",
+	OrderStatusDeclined:   "declined",
+}
+
+// orderStatusTransitions are the statuses the gateway moves an order to from the given one.
+var orderStatusTransitions = map[OrderStatus][]OrderStatus{
+	OrderStatusRegistered: {OrderStatusACSAuth, OrderStatusApproved, OrderStatusDeposited, OrderStatusDeclined},
+	OrderStatusACSAuth:    {OrderStatusApproved, OrderStatusDeposited, OrderStatusDeclined},
+	OrderStatusApproved:   {OrderStatusDeposited, OrderStatusReversed},
+	OrderStatusDeposited:  {OrderStatusReversed, OrderStatusRefunded},
+}
+
+func (s OrderStatus) String() string {
+	if name, ok := orderStatusNames[s]; ok {
+		return name
+	}
+
+	return "OrderStatus(" + strconv.Itoa(int(s)) + ")"
+}
+
+// IsPaid reports whether the customer has paid the order, the amount is held or deposited.
+func (s OrderStatus) IsPaid() bool {
+	return s == OrderStatusApproved || s == OrderStatusDeposited
+}
+
+// IsFinal reports whether the customer can no longer change the status of the order,
+// later changes are made by deposit, reverse and refund requests of the merchant.
+func (s OrderStatus) IsFinal() bool {
+	switch s {
+	case OrderStatusApproved, OrderStatusDeposited, OrderStatusReversed, OrderStatusRefunded, OrderStatusDeclined:
+		return true
+	}
+
+	return false
+}
+
+// CanDeposit reports whether the held amount may be deposited.
+func (s OrderStatus) CanDeposit() bool {
+	return s == OrderStatusApproved
+}
+
+// CanReverse reports whether the payment may be cancelled. The gateway reverses
+// deposited payments only until the end of the operational day.
+func (s OrderStatus) CanReverse() bool {
+	return s == OrderStatusApproved || s == OrderStatusDeposited
+}
+
+// CanTransitionTo reports whether the gateway may change the status of the order to next.
+func (s OrderStatus) CanTransitionTo(next OrderStatus) bool {
+	return s == next || slices.Contains(orderStatusTransitions[s], next)
+}
+
+// ValidateTransition returns ErrInvalidTransition when the order can't change its status from one to another,
+// e.g. an order service may reject an outdated or tampered status received in a callback.
+func ValidateTransition(from, to OrderStatus) error {
+	if _, ok := orderStatusNames[to]; !ok {
+		return fmt.Errorf("%w: unknown status %d", ErrInvalidTransition, int(to))
+	}
+	if !from.CanTransitionTo(to) {
+		return fmt.Errorf("%w: %s to %s", ErrInvalidTransition, from, to)
+	}
+
+	return nil
+}
+
+// PaymentState is the paymentState of paymentAmountInfo
+type PaymentState string
+
+const (
+	PaymentStateCreated   PaymentState = "CREATED"
+	PaymentStateApproved  PaymentState = "APPROVED"
+	PaymentStateDeposited PaymentState = "DEPOSITED"
+	PaymentStateDeclined  PaymentState = "DECLINED"
+	PaymentStateReversed  PaymentState = "REVERSED"
+	PaymentStateRefunded  PaymentState = "REFUNDED"
+)
+
+var paymentStateStatuses = map[PaymentState]OrderStatus{
+	PaymentStateCreated:   OrderStatusRegistered,
+	PaymentStateApproved:  OrderStatusApproved,
+	PaymentStateDeposited: OrderStatusDeposited,
+	PaymentStateDeclined:  OrderStatusDeclined,
+	PaymentStateReversed:  OrderStatusReversed,
+	PaymentStateRefunded:  OrderStatusRefunded,
+}
+
+// OrderStatus returns the orderStatus matching the payment state, ok is false for unknown states.
+func (s PaymentState) OrderStatus() (status OrderStatus, ok bool) {
+	status, ok = paymentStateStatuses[s]
+	return status, ok
+}
+
+// IsPaid reports whether the amount is held or deposited.
+func (s PaymentState) IsPaid() bool {
+	status, ok := s.OrderStatus()
+	return ok && status.IsPaid()
+}
+
+// IsFinal reports whether the customer can no longer change the state of the payment.
+func (s PaymentState) IsFinal() bool {
+	status, ok := s.OrderStatus()
+	return ok && status.IsFinal()
+}
+
+// CanDeposit reports whether the held amount may be deposited.
+func (s PaymentState) CanDeposit() bool {
+	status, ok := s.OrderStatus()
+	return ok && status.CanDeposit()
+}
+
+// CanReverse reports whether the payment may be cancelled.
+func (s PaymentState) CanReverse() bool {
+	status, ok := s.OrderStatus()
+	return ok && status.CanReverse()
+}
+
+// CanRefund reports whether the amount may be refunded: the payment is deposited and the amount
+// doesn't exceed refundable, the deposited amount left after previous refunds.
+func (s PaymentState) CanRefund(amount, refundable int) bool {
+	if s != PaymentStateDeposited && s != PaymentStateRefunded {
+		return false
+	}
+
+	return amount > 0 && amount <= refundable
+}
+
+// CanRefund reports whether the amount may be refunded: the order is deposited and the amount
+// doesn't exceed the deposited amount left after previous refunds.
+func (r OrderStatusResponse) CanRefund(amount int) bool {
+	if r.OrderStatus != OrderStatusDeposited && r.OrderStatus != OrderStatusRefunded {
+		return false
+	}
+
+	return amount > 0 && amount <= r.PaymentAmountInfo.DepositedAmount-r.PaymentAmountInfo.RefundedAmount
+}
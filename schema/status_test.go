@@ -0,0 +1,94 @@
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestOrderStatus(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("Predicates", func(t *testing.T) {
+		Expect(OrderStatusApproved.IsPaid()).To(BeTrue())
+		Expect(OrderStatusDeposited.IsPaid()).To(BeTrue())
+		Expect(OrderStatusRefunded.IsPaid()).To(BeFalse())
+
+		Expect(OrderStatusRegistered.IsFinal()).To(BeFalse())
+		Expect(OrderStatusACSAuth.IsFinal()).To(BeFalse())
+		Expect(OrderStatusDeclined.IsFinal()).To(BeTrue())
+		Expect(OrderStatusApproved.IsFinal()).To(BeTrue())
+
+		Expect(OrderStatusApproved.CanDeposit()).To(BeTrue())
+		Expect(OrderStatusDeposited.CanDeposit()).To(BeFalse())
+		Expect(OrderStatusDeposited.CanReverse()).To(BeTrue())
+		Expect(OrderStatusRefunded.CanReverse()).To(BeFalse())
+	})
+
+	t.Run("Payment state predicates", func(t *testing.T) {
+		tests := []struct {
+			state      PaymentState
+			status     OrderStatus
+			known      bool
+			paid       bool
+			final      bool
+			canDeposit bool
+			canReverse bool
+			canRefund  bool
+		}{
+			{state: PaymentStateCreated, status: OrderStatusRegistered, known: true},
+			{state: PaymentStateApproved, status: OrderStatusApproved, known: true, paid: true, final: true, canDeposit: true, canReverse: true},
+			{state: PaymentStateDeposited, status: OrderStatusDeposited, known: true, paid: true, final: true, canReverse: true, canRefund: true},
+			{state: PaymentStateDeclined, status: OrderStatusDeclined, known: true, final: true},
+			{state: PaymentStateReversed, status: OrderStatusReversed, known: true, final: true},
+			{state: PaymentStateRefunded, status: OrderStatusRefunded, known: true, final: true, canRefund: true},
+			{state: PaymentState("PAID")},
+		}
+
+		for _, tt := range tests {
+			status, ok := tt.state.OrderStatus()
+			Expect(ok).To(Equal(tt.known), string(tt.state))
+			Expect(status).To(Equal(tt.status), string(tt.state))
+			Expect(tt.state.IsPaid()).To(Equal(tt.paid), string(tt.state))
+			Expect(tt.state.IsFinal()).To(Equal(tt.final), string(tt.state))
+			Expect(tt.state.CanDeposit()).To(Equal(tt.canDeposit), string(tt.state))
+			Expect(tt.state.CanReverse()).To(Equal(tt.canReverse), string(tt.state))
+			Expect(tt.state.CanRefund(100, 100)).To(Equal(tt.canRefund), string(tt.state))
+			Expect(tt.state.CanRefund(101, 100)).To(BeFalse(), string(tt.state))
+			Expect(tt.state.CanRefund(0, 100)).To(BeFalse(), string(tt.state))
+		}
+	})
+
+	t.Run("String", func(t *testing.T) {
+		Expect(OrderStatusACSAuth.String()).To(Equal("acs_auth"))
+		Expect(OrderStatus(9).String()).To(Equal("OrderStatus(9)"))
+	})
+
+	t.Run("Transitions", func(t *testing.T) {
+		Expect(ValidateTransition(OrderStatusRegistered, OrderStatusACSAuth)).To(Succeed())
+		Expect(ValidateTransition(OrderStatusACSAuth, OrderStatusDeposited)).To(Succeed())
+		Expect(ValidateTransition(OrderStatusApproved, OrderStatusDeposited)).To(Succeed())
+		Expect(ValidateTransition(OrderStatusDeposited, OrderStatusRefunded)).To(Succeed())
+		Expect(ValidateTransition(OrderStatusRefunded, OrderStatusRefunded)).To(Succeed())
+
+		Expect(ValidateTransition(OrderStatusDeposited, OrderStatusRegistered)).To(MatchError("invalid order status transition: deposited to registered"))
+		Expect(ValidateTransition(OrderStatusDeclined, OrderStatusDeposited)).To(MatchError(ErrInvalidTransition))
+		Expect(ValidateTransition(OrderStatusRegistered, OrderStatusRefunded)).To(MatchError(ErrInvalidTransition))
+		Expect(ValidateTransition(OrderStatusRegistered, OrderStatus(9))).To(MatchError("invalid order status transition: unknown status 9"))
+	})
+
+	t.Run("CanRefund", func(t *testing.T) {
+		var status OrderStatusResponse
+		Expect(json.Unmarshal([]byte(`{"orderStatus":4,"paymentAmountInfo":{"paymentState":"REFUNDED","depositedAmount":1000,"refundedAmount":300}}`), &status)).To(Succeed())
+		Expect(status.OrderStatus).To(Equal(OrderStatusRefunded))
+		Expect(status.PaymentAmountInfo.PaymentState).To(Equal(PaymentStateRefunded))
+
+		Expect(status.CanRefund(700)).To(BeTrue())
+		Expect(status.CanRefund(701)).To(BeFalse())
+		Expect(status.CanRefund(0)).To(BeFalse())
+
+		status.OrderStatus = OrderStatusApproved
+		Expect(status.CanRefund(100)).To(BeFalse())
+	})
+}
@@ -0,0 +1,177 @@
+package sberbank_acquiring_go
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// DefaultTimeout limits the time of a single request of a client without WithTimeout or WithHTTPClient.
+const DefaultTimeout = 60 * time.Second
+
+// ErrCertificateNotPinned is returned when no certificate presented by the gateway matches the pins of WithPinnedSPKI.
+var ErrCertificateNotPinned = errors.New("gateway certificate doesn't match pinned public keys")
+
+// transportOptions are the settings of the client transport.
+type transportOptions struct {
+	roundTripper http.RoundTripper
+	proxy        *url.URL
+	certificates []tls.Certificate
+	pins         [][]byte
+	// prepared is set when the http.Client has been prepared with these options,
+	// so copies of the client made by GetAPI share its transport and connections
+	prepared bool
+}
+
+// WithTransport configures a Client to send requests with the specified http.RoundTripper.
+// It replaces the transport of the http.Client passed with WithHTTPClient.
+func WithTransport(roundTripper http.RoundTripper) ClientOption {
+	return func(c *Client) {
+		c.transport.roundTripper = roundTripper
+		c.transport.prepared = false
+	}
+}
+
+// WithProxy configures a Client to send requests through the specified proxy,
+// HTTPS_PROXY and related environment variables are used otherwise.
+func WithProxy(proxy *url.URL) ClientOption {
+	return func(c *Client) {
+		c.transport.proxy = proxy
+		c.transport.prepared = false
+	}
+}
+
+// WithClientCertificate configures a Client to present the certificate
+// to acquirers that require mutual TLS.
+func WithClientCertificate(certificate tls.Certificate) ClientOption {
+	return func(c *Client) {
+		c.transport.certificates = append(c.transport.certificates[:len(c.transport.certificates):len(c.transport.certificates)], certificate)
+		c.transport.prepared = false
+	}
+}
+
+// WithClientCertificateFile is WithClientCertificate that loads a PEM encoded certificate and key.
+// Load errors are returned by NewClient.
+func WithClientCertificateFile(certFile, keyFile string) ClientOption {
+	return func(c *Client) {
+		certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			c.setErr(fmt.Errorf("unable to load client certificate: %w", err))
+			return
+		}
+		WithClientCertificate(certificate)(c)
+	}
+}
+
+// WithPinnedSPKI configures a Client to accept only gateway certificates whose public key
+// matches one of the pins: base64 encoded SHA-256 of the SubjectPublicKeyInfo, see SPKIPin.
+// A certificate chain in use is still verified. Invalid pins are reported by NewClient.
+//
+//	openssl x509 -in cert.pem -pubkey -noout | openssl pkey -pubin -outform der | openssl dgst -sha256 -binary | base64
+func WithPinnedSPKI(pins ...string) ClientOption {
+	return func(c *Client) {
+		decoded := make([][]byte, 0, len(pins))
+		for _, pin := range pins {
+			hash, err := base64.StdEncoding.DecodeString(pin)
+			if err != nil || len(hash) != sha256.Size {
+				c.setErr(fmt.Errorf("invalid SPKI pin %q: SHA-256 in base64 expected", pin))
+				return
+			}
+			decoded = append(decoded, hash)
+		}
+		c.transport.pins = decoded
+		c.transport.prepared = false
+	}
+}
+
+// SPKIPin returns the pin of the certificate public key for WithPinnedSPKI.
+func SPKIPin(certificate *x509.Certificate) string {
+	hash := sha256.Sum256(certificate.RawSubjectPublicKeyInfo)
+
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
+
+// setErr keeps the first configuration error of the client.
+func (c *Client) setErr(err error) {
+	if c.err == nil {
+		c.err = err
+	}
+}
+
+// prepareTransport sets the transport of the client http.Client according to transport options.
+func (c *Client) prepareTransport() error {
+	if c.transport.prepared {
+		return nil
+	}
+	options := c.transport
+	roundTripper := options.roundTripper
+	if roundTripper == nil {
+		roundTripper = c.httpClient.Transport
+	}
+
+	if options.proxy != nil || len(options.certificates) > 0 || len(options.pins) > 0 {
+		var transport *http.Transport
+		switch base := roundTripper.(type) {
+		case nil:
+			transport = http.DefaultTransport.(*http.Transport).Clone()
+		case *http.Transport:
+			transport = base.Clone()
+		default:
+			return fmt.Errorf("proxy, client certificate and pinning require *http.Transport, got %T", roundTripper)
+		}
+
+		if options.proxy != nil {
+			transport.Proxy = http.ProxyURL(options.proxy)
+		}
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+		if len(options.certificates) > 0 {
+			transport.TLSClientConfig.Certificates = options.certificates
+		}
+		if len(options.pins) > 0 {
+			transport.TLSClientConfig.VerifyConnection = verifyPins(options.pins)
+		}
+		roundTripper = transport
+	}
+
+	if roundTripper != c.httpClient.Transport {
+		httpClient := *c.httpClient
+		httpClient.Transport = roundTripper
+		c.httpClient = &httpClient
+	}
+	c.transport.prepared = true
+
+	return nil
+}
+
+// verifyPins checks the pins against the verified certificate chains. Certificates sent by the gateway
+// may contain any certificate appended to the chain, so only the leaf is checked when verification is skipped.
+func verifyPins(pins [][]byte) func(tls.ConnectionState) error {
+	return func(state tls.ConnectionState) error {
+		chains := state.VerifiedChains
+		if len(chains) == 0 && len(state.PeerCertificates) > 0 {
+			chains = [][]*x509.Certificate{state.PeerCertificates[:1]}
+		}
+
+		for _, chain := range chains {
+			for _, certificate := range chain {
+				hash := sha256.Sum256(certificate.RawSubjectPublicKeyInfo)
+				for _, pin := range pins {
+					if bytes.Equal(hash[:], pin) {
+						return nil
+					}
+				}
+			}
+		}
+
+		return ErrCertificateNotPinned
+	}
+}
@@ -0,0 +1,270 @@
+package sberbank_acquiring_go
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/helios-ag/sberbank-acquiring-go/endpoints"
+	. "github.com/onsi/gomega"
+)
+
+var testConfig = ClientConfig{UserName: "test-api", Password: "test"}
+
+func newTLSServer() *httptest.Server {
+	return httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"errorCode":"0","orderStatus":2}`)
+	}))
+}
+
+func getOrderStatus(client *Client) error {
+	req, err := client.NewRestRequest(context.Background(), http.MethodPost, endpoints.GetOrderStatusExtended, map[string]string{"orderId": "42"}, nil)
+	if err != nil {
+		return err
+	}
+	_, err = client.Do(req, nil)
+
+	return err
+}
+
+// countingTransport counts requests sent through the wrapped transport.
+type countingTransport struct {
+	http.RoundTripper
+	requests atomic.Int32
+}
+
+func (t *countingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	t.requests.Add(1)
+	return t.RoundTripper.RoundTrip(r)
+}
+
+// clientCertificate returns a self-signed certificate for client authentication.
+func clientCertificate() (tls.Certificate, *x509.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).ToNot(HaveOccurred())
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "merchant"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	Expect(err).ToNot(HaveOccurred())
+	certificate, err := x509.ParseCertificate(der)
+	Expect(err).ToNot(HaveOccurred())
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: certificate}, certificate
+}
+
+// mustDecodePin returns the SHA-256 hash of the pin.
+func mustDecodePin(pin string) []byte {
+	hash, err := base64.StdEncoding.DecodeString(pin)
+	Expect(err).ToNot(HaveOccurred())
+
+	return hash
+}
+
+// connectProxy is a minimal HTTPS proxy that tunnels CONNECT requests.
+func connectProxy(tunnels *atomic.Int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodConnect {
+			http.Error(w, "CONNECT expected", http.StatusMethodNotAllowed)
+			return
+		}
+		upstream, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		tunnels.Add(1)
+		w.WriteHeader(http.StatusOK)
+		conn, _, _ := w.(http.Hijacker).Hijack()
+		go func() {
+			_, _ = io.Copy(upstream, conn)
+			upstream.Close()
+		}()
+		_, _ = io.Copy(conn, upstream)
+		conn.Close()
+	}))
+}
+
+func TestDefaultTimeout(t *testing.T) {
+	RegisterTestingT(t)
+
+	client, err := NewClient(testConfig)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(client.httpClient.Timeout).To(Equal(DefaultTimeout))
+
+	client, err = NewClient(testConfig, WithHTTPClient(&http.Client{}))
+	Expect(err).ToNot(HaveOccurred())
+	Expect(client.httpClient.Timeout).To(BeZero())
+}
+
+func TestWithTransport(t *testing.T) {
+	RegisterTestingT(t)
+
+	tlsServer := newTLSServer()
+	defer tlsServer.Close()
+
+	transport := &countingTransport{RoundTripper: tlsServer.Client().Transport}
+	httpClient := &http.Client{Timeout: time.Second}
+	client, err := NewClient(testConfig, WithEndpoint(tlsServer.URL), WithHTTPClient(httpClient), WithTransport(transport))
+	Expect(err).ToNot(HaveOccurred())
+
+	Expect(getOrderStatus(client)).To(Succeed())
+	Expect(transport.requests.Load()).To(BeEquivalentTo(1))
+	Expect(client.httpClient.Timeout).To(Equal(time.Second))
+	Expect(httpClient.Transport).To(BeNil(), "passed http.Client is not modified")
+}
+
+func TestWithProxy(t *testing.T) {
+	RegisterTestingT(t)
+
+	tlsServer := newTLSServer()
+	defer tlsServer.Close()
+
+	var tunnels atomic.Int32
+	proxy := connectProxy(&tunnels)
+	defer proxy.Close()
+	proxyURL, _ := url.Parse(proxy.URL)
+
+	client, err := NewClient(testConfig, WithEndpoint(tlsServer.URL), WithHTTPClient(tlsServer.Client()), WithProxy(proxyURL))
+	Expect(err).ToNot(HaveOccurred())
+
+	Expect(getOrderStatus(client)).To(Succeed())
+	Expect(tunnels.Load()).To(BeEquivalentTo(1))
+
+	t.Run("GetAPI copies reuse connections", func(t *testing.T) {
+		tunnels.Store(0)
+		SetConfig(testConfig, WithEndpoint(tlsServer.URL), WithHTTPClient(tlsServer.Client()), WithProxy(proxyURL))
+		defer SetConfig(ClientConfig{})
+
+		for range 3 {
+			Expect(getOrderStatus(GetAPI(WithUserAgent("shop")).(*Client))).To(Succeed())
+		}
+		Expect(tunnels.Load()).To(BeEquivalentTo(1))
+		Expect(GetAPI(WithUserAgent("shop")).(*Client).httpClient.Transport).To(BeIdenticalTo(GetAPI().(*Client).httpClient.Transport))
+		Expect(GetAPI(WithProxy(proxyURL)).(*Client).httpClient.Transport).ToNot(BeIdenticalTo(GetAPI().(*Client).httpClient.Transport))
+	})
+}
+
+func TestWithClientCertificate(t *testing.T) {
+	RegisterTestingT(t)
+
+	certificate, leaf := clientCertificate()
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(leaf)
+
+	var peer string
+	tlsServer := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		peer = r.TLS.PeerCertificates[0].Subject.CommonName
+		fmt.Fprint(w, `{"errorCode":"0"}`)
+	}))
+	tlsServer.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	tlsServer.StartTLS()
+	defer tlsServer.Close()
+
+	t.Run("Certificate is presented", func(t *testing.T) {
+		client, err := NewClient(testConfig, WithEndpoint(tlsServer.URL), WithHTTPClient(tlsServer.Client()), WithClientCertificate(certificate))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(getOrderStatus(client)).To(Succeed())
+		Expect(peer).To(Equal("merchant"))
+	})
+
+	t.Run("Handshake fails without certificate", func(t *testing.T) {
+		client, err := NewClient(testConfig, WithEndpoint(tlsServer.URL), WithHTTPClient(tlsServer.Client()))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(getOrderStatus(client)).ToNot(Succeed())
+	})
+
+	t.Run("Missing certificate file", func(t *testing.T) {
+		_, err := NewClient(testConfig, WithClientCertificateFile("missing.pem", "missing.key"))
+		Expect(err).To(MatchError(ContainSubstring("unable to load client certificate")))
+	})
+}
+
+func TestWithPinnedSPKI(t *testing.T) {
+	RegisterTestingT(t)
+
+	tlsServer := newTLSServer()
+	defer tlsServer.Close()
+
+	_, other := clientCertificate()
+
+	t.Run("Pinned key is accepted", func(t *testing.T) {
+		client, err := NewClient(testConfig, WithEndpoint(tlsServer.URL), WithHTTPClient(tlsServer.Client()),
+			WithPinnedSPKI(SPKIPin(other), SPKIPin(tlsServer.Certificate())))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(getOrderStatus(client)).To(Succeed())
+	})
+
+	t.Run("Other key is rejected", func(t *testing.T) {
+		client, err := NewClient(testConfig, WithEndpoint(tlsServer.URL), WithHTTPClient(tlsServer.Client()), WithPinnedSPKI(SPKIPin(other)))
+		Expect(err).ToNot(HaveOccurred())
+
+		err = getOrderStatus(client)
+		Expect(errors.Is(err, ErrCertificateNotPinned)).To(BeTrue(), "unexpected error %v", err)
+	})
+
+	t.Run("Pinned certificate appended to an unrelated chain is rejected", func(t *testing.T) {
+		appending := httptest.NewUnstartedServer(tlsServer.Config.Handler)
+		appending.StartTLS()
+		defer appending.Close()
+		served := &appending.TLS.Certificates[0]
+		served.Certificate = append(served.Certificate[:len(served.Certificate):len(served.Certificate)], other.Raw)
+
+		client, err := NewClient(testConfig, WithEndpoint(appending.URL), WithHTTPClient(appending.Client()), WithPinnedSPKI(SPKIPin(other)))
+		Expect(err).ToNot(HaveOccurred())
+
+		err = getOrderStatus(client)
+		Expect(errors.Is(err, ErrCertificateNotPinned)).To(BeTrue(), "unexpected error %v", err)
+	})
+
+	t.Run("Only the leaf is checked without verified chains", func(t *testing.T) {
+		verify := verifyPins([][]byte{mustDecodePin(SPKIPin(other))})
+
+		Expect(verify(tls.ConnectionState{PeerCertificates: []*x509.Certificate{tlsServer.Certificate(), other}})).To(MatchError(ErrCertificateNotPinned))
+		Expect(verify(tls.ConnectionState{PeerCertificates: []*x509.Certificate{other}})).To(Succeed())
+		Expect(verify(tls.ConnectionState{})).To(MatchError(ErrCertificateNotPinned))
+	})
+
+	t.Run("Invalid pin", func(t *testing.T) {
+		_, err := NewClient(testConfig, WithPinnedSPKI("not a pin"))
+		Expect(err).To(MatchError(ContainSubstring("invalid SPKI pin")))
+	})
+
+	t.Run("Default client reports configuration errors on Do", func(t *testing.T) {
+		SetConfig(testConfig, WithEndpoint(tlsServer.URL), WithPinnedSPKI("not a pin"))
+		defer SetConfig(ClientConfig{})
+
+		err := getOrderStatus(GetAPI().(*Client))
+		Expect(err).To(MatchError(ContainSubstring("invalid SPKI pin")))
+	})
+
+	t.Run("Custom round tripper can't be pinned", func(t *testing.T) {
+		_, err := NewClient(testConfig, WithTransport(staticTransport("{}")), WithPinnedSPKI(SPKIPin(other)))
+		Expect(err).To(MatchError(ContainSubstring("require *http.Transport")))
+	})
+}